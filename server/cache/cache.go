@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/global"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache 缓存接口，便于替换为不同的缓存实现
+type Cache interface {
+	// Get 获取缓存，缓存不存在时 ok 为 false
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	// Set 设置缓存，ttl 为 0 时不过期
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// SetNX 缓存不存在时设置缓存，返回是否设置成功
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Incr 将缓存的值加一并返回加一后的值，缓存不存在时从 0 开始
+	Incr(ctx context.Context, key string) (int64, error)
+	// Del 删除缓存
+	Del(ctx context.Context, keys ...string) error
+}
+
+// RedisCache 基于 redis 的缓存实现
+type RedisCache struct{}
+
+func NewRedisCache() *RedisCache {
+	return &RedisCache{}
+}
+
+func (*RedisCache) Get(ctx context.Context, key string) (string, bool, error) {
+
+	value, err := global.RedisCli.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (*RedisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+
+	return global.RedisCli.Set(ctx, key, value, ttl).Err()
+}
+
+func (*RedisCache) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+
+	return global.RedisCli.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (*RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+
+	return global.RedisCli.Incr(ctx, key).Result()
+}
+
+func (*RedisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return global.RedisCli.Del(ctx, keys...).Err()
+}
+
+// MemoryCache 基于内存的缓存实现，用于测试或单机部署
+type MemoryCache struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value    string
+	expireAt time.Time // 零值表示不过期
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{items: make(map[string]memoryItem)}
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.getItem(key)
+	return item.value, ok, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
+	c.items[key] = item
+	return nil
+}
+
+func (c *MemoryCache) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.getItem(key); ok {
+		return false, nil
+	}
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
+	c.items[key] = item
+	return true, nil
+}
+
+func (c *MemoryCache) Incr(_ context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var num int64
+	item, ok := c.getItem(key)
+	if ok {
+		var err error
+		if num, err = strconv.ParseInt(item.value, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	num++
+	item.value = strconv.FormatInt(num, 10)
+	c.items[key] = item
+	return num, nil
+}
+
+func (c *MemoryCache) Del(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.items, key)
+	}
+	return nil
+}
+
+// getItem 获取未过期的缓存，已过期的缓存会被删除
+func (c *MemoryCache) getItem(key string) (memoryItem, bool) {
+	item, ok := c.items[key]
+	if !ok {
+		return memoryItem{}, false
+	}
+	if !item.expireAt.IsZero() && time.Now().After(item.expireAt) {
+		delete(c.items, key)
+		return memoryItem{}, false
+	}
+	return item, true
+}
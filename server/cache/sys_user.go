@@ -16,6 +16,8 @@ var (
 
 const (
 	SysUserStatusKey = "sys:user:status:"
+
+	SysUserTokenVersionKey = "sys:user:token-version:"
 )
 
 type sysUserCache struct{}
@@ -46,3 +48,46 @@ func (cache *sysUserCache) GetSysUserStatus(userId uint64) (status uint8, err er
 		return user.Status, nil
 	}
 }
+
+func (*sysUserCache) SetSysUserTokenVersion(userId uint64, version int) {
+
+	ctx := context.Background()
+	global.RedisCli.Set(ctx, SysUserTokenVersionKey+strconv.FormatUint(userId, 10), version, time.Hour*24*7)
+}
+
+func (cache *sysUserCache) GetSysUserTokenVersion(userId uint64) (version int, err error) {
+	ctx := context.Background()
+	result, err := global.RedisCli.Get(ctx, SysUserTokenVersionKey+strconv.FormatUint(userId, 10)).Result()
+	if err == nil {
+		if i, err2 := strconv.Atoi(result); err2 == nil {
+			return i, nil
+		}
+	}
+
+	// 从数据库中获取令牌版本号
+	var user model.SysUser
+	if err = global.GormDB.Model(&model.SysUser{}).Where("id = ?", userId).Select("token_version").First(&user).Error; err != nil {
+		zap.L().Error("Get sys user token version from db error: ", zap.Error(err))
+		return 0, buserr.NewNoticeBusErr("网络错误，请稍后再试！")
+	} else {
+		// 重新缓存数据
+		cache.SetSysUserTokenVersion(userId, user.TokenVersion)
+		return user.TokenVersion, nil
+	}
+}
+
+// DelSysUserTokenVersion 删除用户令牌版本号缓存，下次校验时从数据库重新加载
+func (*sysUserCache) DelSysUserTokenVersion(userIds ...uint64) {
+	if len(userIds) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(userIds))
+	for i := range userIds {
+		keys = append(keys, SysUserTokenVersionKey+strconv.FormatUint(userIds[i], 10))
+	}
+	ctx := context.Background()
+	if err := global.RedisCli.Del(ctx, keys...).Err(); err != nil {
+		zap.L().Error("Delete sys user token version cache error: ", zap.Error(err))
+	}
+}
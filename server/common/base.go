@@ -1,6 +1,7 @@
 package common
 
 import (
+	"gitee.com/nichanghao/gdmin/common/buserr"
 	"gorm.io/gorm"
 	"strconv"
 	"time"
@@ -8,9 +9,13 @@ import (
 
 // BaseDO 基础模型
 type BaseDO struct {
+	CreatedAt  time.Time      `gorm:"comment:创建时间" json:"createdAt"`
 	UpdatedAt  time.Time      `gorm:"comment:修改时间" json:"-"`
 	ModifyUser string         `gorm:"comment:修改人" json:"-"`
-	DeletedAt  gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"` // gorm逻辑删除
+	DeletedAt  gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"`          // gorm逻辑删除
+	Version    int            `gorm:"default:0;comment:版本号" json:"version"` // 乐观锁版本号
+	CreatedBy  uint64         `gorm:"default:0;comment:创建人ID" json:"createdBy"`
+	UpdatedBy  uint64         `gorm:"default:0;comment:修改人ID" json:"updatedBy"`
 }
 
 // BeforeSave 在保存之前执行
@@ -23,6 +28,26 @@ func (u *BaseDO) BeforeSave(tx *gorm.DB) (err error) {
 	return nil
 }
 
+// BeforeCreate 在新增之前执行，上下文中没有登录用户时（如初始化数据）创建人和修改人为 0
+func (u *BaseDO) BeforeCreate(tx *gorm.DB) (err error) {
+	ctx := tx.Statement.Context
+
+	userId := USER_CTX.GetUserId(&ctx)
+	u.CreatedBy = userId
+	u.UpdatedBy = userId
+	return nil
+}
+
+// BeforeUpdate 在更新之前执行，通过 SetColumn 设置修改人，使 Update 和 Updates(map) 同样生效
+func (u *BaseDO) BeforeUpdate(tx *gorm.DB) (err error) {
+	ctx := tx.Statement.Context
+
+	if userId := USER_CTX.GetUserId(&ctx); userId != 0 {
+		tx.Statement.SetColumn("UpdatedBy", userId)
+	}
+	return nil
+}
+
 // BeforeDelete 在删除之前执行
 func (u *BaseDO) BeforeDelete(tx *gorm.DB) (err error) {
 	ctx := tx.Statement.Context
@@ -32,3 +57,27 @@ func (u *BaseDO) BeforeDelete(tx *gorm.DB) (err error) {
 	}
 	return nil
 }
+
+// SetVersion 设置版本号
+func (u *BaseDO) SetVersion(version int) {
+	u.Version = version
+}
+
+// UpdateWithVersion 基于版本号的乐观锁更新，model 需嵌入 BaseDO。
+// 仅当数据库中的版本号与 expectedVersion 一致时才更新并将版本号加一，否则返回 buserr.ErrStaleObject
+func UpdateWithVersion(db *gorm.DB, model interface{}, expectedVersion int) error {
+
+	if versioned, ok := model.(interface{ SetVersion(int) }); ok {
+		versioned.SetVersion(expectedVersion + 1)
+	}
+
+	result := db.Where("version = ?", expectedVersion).Updates(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return buserr.ErrStaleObject
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package common
+
+import (
+	"errors"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type versionedItem struct {
+	Id   uint64 `gorm:"primarykey"`
+	Name string
+	BaseDO
+}
+
+func newVersionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "version.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&versionedItem{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Create(&versionedItem{Name: "init"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUpdateWithVersion(t *testing.T) {
+	db := newVersionTestDB(t)
+
+	// 两个页面先后读取到相同的版本号
+	var tabA, tabB versionedItem
+	db.First(&tabA, 1)
+	db.First(&tabB, 1)
+
+	if err := UpdateWithVersion(db.Model(&versionedItem{}).Where("id = ?", 1), &versionedItem{Name: "a"}, tabA.Version); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	err := UpdateWithVersion(db.Model(&versionedItem{}).Where("id = ?", 1), &versionedItem{Name: "b"}, tabB.Version)
+	if !errors.Is(err, buserr.ErrStaleObject) {
+		t.Fatalf("second update err = %v, want ErrStaleObject", err)
+	}
+
+	var got versionedItem
+	db.First(&got, 1)
+	if got.Name != "a" || got.Version != tabA.Version+1 {
+		t.Fatalf("got name=%q version=%d, want name=%q version=%d", got.Name, got.Version, "a", tabA.Version+1)
+	}
+}
+
+func TestUpdateWithVersionConcurrent(t *testing.T) {
+	db := newVersionTestDB(t)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = UpdateWithVersion(db.Model(&versionedItem{}).Where("id = ?", 1), &versionedItem{Name: "writer"}, 0)
+		}(i)
+	}
+	wg.Wait()
+
+	var succeeded int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, buserr.ErrStaleObject):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d writers succeeded with the same version, want 1", succeeded)
+	}
+
+	var got versionedItem
+	db.First(&got, 1)
+	if got.Version != 1 {
+		t.Fatalf("version = %d, want 1", got.Version)
+	}
+}
@@ -0,0 +1,74 @@
+package buserr
+
+import (
+	"errors"
+	"sync"
+)
+
+// 业务错误码，前端可根据code码做特定的处理，已发布的code码不能修改
+const (
+	StaleObjectCode      = 20002
+	PermissionDeniedCode = 20003
+	NotFoundCode         = 20004
+	DuplicateKeyCode     = 20005
+	ForeignKeyCode       = 20006
+	QueryTimeoutCode     = 20007
+	DatabaseCode         = 20008
+	InvalidCursorCode    = 20009
+
+	RoleNotFoundCode         = 21001
+	ReservedRoleCode         = 21002
+	RoleCycleCode            = 21003
+	RoleCodeConflictCode     = 21004
+	RoleInUseCode            = 21005
+	RoleRevisionNotFoundCode = 21006
+)
+
+type codeEntry struct {
+	err  error
+	code int
+}
+
+var (
+	codeMu       sync.RWMutex
+	codeRegistry = []codeEntry{
+		{ErrStaleObject, StaleObjectCode},
+		{ErrPermissionDenied, PermissionDeniedCode},
+		{ErrNotFound, NotFoundCode},
+		{ErrDuplicateKey, DuplicateKeyCode},
+		{ErrForeignKey, ForeignKeyCode},
+		{ErrQueryTimeout, QueryTimeoutCode},
+		{ErrDatabase, DatabaseCode},
+		{ErrInvalidCursor, InvalidCursorCode},
+		{ErrRoleNotFound, RoleNotFoundCode},
+		{ErrReservedRole, ReservedRoleCode},
+		{ErrRoleCycle, RoleCycleCode},
+		{ErrRoleCodeConflict, RoleCodeConflictCode},
+		{ErrRoleInUse, RoleInUseCode},
+		{ErrRoleRevisionNotFound, RoleRevisionNotFoundCode},
+	}
+)
+
+// RegisterCode 注册错误对应的业务错误码，通过 errors.Is 匹配
+func RegisterCode(err error, code int) {
+	codeMu.Lock()
+	defer codeMu.Unlock()
+	codeRegistry = append(codeRegistry, codeEntry{err, code})
+}
+
+// CodeOf 获取错误对应的业务错误码，未注册时使用业务异常自身的code码
+func CodeOf(err error) (int, bool) {
+	codeMu.RLock()
+	defer codeMu.RUnlock()
+	for _, entry := range codeRegistry {
+		if errors.Is(err, entry.err) {
+			return entry.code, true
+		}
+	}
+
+	var busErr *BusinessError
+	if errors.As(err, &busErr) {
+		return busErr.Code, true
+	}
+	return 0, false
+}
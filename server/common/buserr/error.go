@@ -1,8 +1,30 @@
 package buserr
 
+import (
+	"fmt"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"strings"
+)
+
 var (
 	ErrPermissionDenied = NewNoticeBusErr("权限不足，请联系管理员分配权限！")
 	ErrIllegalParameter = NewBusErr(20001, "请求参数错误！")
+	ErrStaleObject      = NewNoticeBusErr("数据已被他人修改，请刷新后重试！")
+
+	ErrNotFound      = NewNoticeBusErr("数据不存在！")
+	ErrDuplicateKey  = NewNoticeBusErr("数据已存在，请勿重复添加！")
+	ErrForeignKey    = NewNoticeBusErr("数据存在关联，无法操作！")
+	ErrQueryTimeout  = NewNoticeBusErr("数据库操作超时，请稍后重试！")
+	ErrDatabase      = NewNoticeBusErr("数据库操作失败，请稍后重试！")
+	ErrInvalidCursor = NewNoticeBusErr("分页游标无效，请从第一页重新查询！")
+
+	ErrRoleNotFound     = NewNoticeBusErr("该角色不存在！")
+	ErrReservedRole     = NewNoticeBusErr("内置超级管理员角色不能删除或修改编码！")
+	ErrRoleCycle        = NewNoticeBusErr("角色继承关系存在循环！")
+	ErrRoleCodeConflict = NewNoticeBusErr("角色标识已存在！")
+	ErrRoleInUse        = NewNoticeBusErr("该角色已分配给用户，不能删除！")
+
+	ErrRoleRevisionNotFound = NewNoticeBusErr("角色的历史版本不存在！")
 )
 
 const (
@@ -44,3 +66,70 @@ func NewTokenAuthErr(message string) *BusinessError {
 func (e *BusinessError) Error() string {
 	return e.Message
 }
+
+// RoleInUseError 角色已分配给用户，不能删除
+type RoleInUseError struct {
+	*BusinessError
+	UserCount int64 // 角色关联的用户数量
+}
+
+func NewRoleInUseErr(userCount int64) *RoleInUseError {
+	return &RoleInUseError{
+		BusinessError: NewNoticeBusErr(fmt.Sprintf("该角色已分配给%d个用户，不能删除！", userCount)),
+		UserCount:     userCount,
+	}
+}
+
+func (e *RoleInUseError) messageKey() (string, []any) {
+	return i18n.RoleInUseCount, []any{e.UserCount}
+}
+
+func (e *RoleInUseError) Unwrap() error {
+	return e.BusinessError
+}
+
+// Is 使 errors.Is(err, ErrRoleInUse) 成立
+func (e *RoleInUseError) Is(target error) bool {
+	return target == ErrRoleInUse
+}
+
+// FieldError 字段校验失败的信息
+type FieldError struct {
+	Field   string `json:"field"`   // 字段名
+	Message string `json:"message"` // 失败原因
+
+	key  string // 消息标识，为空时不翻译
+	args []any  // 消息的格式化参数
+}
+
+// ValidationError 参数校验异常，包含所有校验失败的字段，响应的http状态码为400
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Add 添加字段校验失败的信息
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// AddMessage 添加可翻译的字段校验失败的信息，信息默认使用默认语言
+func (e *ValidationError) AddMessage(field, key string, args ...any) {
+	message, _ := i18n.Translate(i18n.DefaultLocale, key, args...)
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message, key: key, args: args})
+}
+
+// ErrOrNil 不存在校验失败的字段时返回 nil
+func (e *ValidationError) ErrOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for i := range e.Fields {
+		messages = append(messages, e.Fields[i].Message)
+	}
+	return strings.Join(messages, "；")
+}
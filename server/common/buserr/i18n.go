@@ -0,0 +1,46 @@
+package buserr
+
+import (
+	"errors"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"strconv"
+)
+
+// localizable 带格式化参数的错误，按返回的消息标识翻译
+type localizable interface {
+	messageKey() (string, []any)
+}
+
+// Localize 按语言翻译错误信息，优先使用错误自身的消息标识，其次使用业务错误码，
+// 都没有翻译时返回错误自身的信息
+func Localize(err error, locale string) string {
+
+	var l localizable
+	if errors.As(err, &l) {
+		key, args := l.messageKey()
+		if message, ok := i18n.Translate(locale, key, args...); ok {
+			return message
+		}
+	}
+	if code, ok := CodeOf(err); ok {
+		if message, ok := i18n.Translate(locale, strconv.Itoa(code)); ok {
+			return message
+		}
+	}
+	return err.Error()
+}
+
+// Localize 按语言翻译字段校验失败的信息，返回新的校验异常
+func (e *ValidationError) Localize(locale string) *ValidationError {
+
+	res := &ValidationError{Fields: make([]FieldError, len(e.Fields))}
+	for i, field := range e.Fields {
+		if field.key != "" {
+			if message, ok := i18n.Translate(locale, field.key, field.args...); ok {
+				field.Message = message
+			}
+		}
+		res.Fields[i] = field
+	}
+	return res
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/global"
+	"gorm.io/gorm"
+	"sync"
+)
+
+// txContextKey 请求上下文中保存事务的key
+type txContextKey struct{}
+
+// TxContext 请求级事务，由事务中间件创建
+type TxContext struct {
+	DB *gorm.DB
+
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// WithTx 将事务保存至上下文
+func WithTx(ctx context.Context, tx *TxContext) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext 获取上下文中的事务，不存在时返回 nil
+func TxFromContext(ctx context.Context) *TxContext {
+	if ctx == nil {
+		return nil
+	}
+	tx, _ := ctx.Value(txContextKey{}).(*TxContext)
+	return tx
+}
+
+// DBFromContext 获取数据库连接，上下文中存在请求级事务时返回该事务，否则返回全局数据库连接。
+// 返回的连接携带 ctx，保证 gorm 钩子中能获取当前用户
+func DBFromContext(ctx context.Context) *gorm.DB {
+	if ctx == nil {
+		return global.GormDB
+	}
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx.DB.WithContext(ctx)
+	}
+	return global.GormDB.WithContext(ctx)
+}
+
+// ModelDB 获取指定 Model 的数据库连接，用于开启事务，事务中的查询默认使用该 Model。
+// 存在请求级事务时开启的是嵌套事务，创建保存点会解析 Model 并固定表名，
+// 使用新会话避免表名被带入事务，导致事务中 tx.Model 指定的其他表不生效
+func ModelDB(ctx context.Context, model any) *gorm.DB {
+	return DBFromContext(ctx).Model(model).Session(&gorm.Session{})
+}
+
+// AfterCommit 注册事务提交后执行的函数，上下文中不存在请求级事务时立即执行。
+// 用于删除缓存等不能回滚的操作，事务回滚时不会执行
+func AfterCommit(ctx context.Context, fn func()) {
+	tx := TxFromContext(ctx)
+	if tx == nil {
+		fn()
+		return
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+// RunAfterCommit 执行事务提交后的函数
+func (tx *TxContext) RunAfterCommit() {
+	tx.mu.Lock()
+	fns := tx.afterCommit
+	tx.afterCommit = nil
+	tx.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// RunInTx 在请求级事务中执行 fn，fn 返回错误或 panic 时回滚事务，提交成功后执行注册的 AfterCommit 函数。
+// 用于无法使用事务中间件的场景，上下文中已存在事务时直接在该事务中执行
+func RunInTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if TxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx := global.GormDB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	txCtx := &TxContext{DB: tx}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(WithTx(ctx, txCtx)); err != nil {
+		return err
+	}
+	if err = tx.Commit().Error; err != nil {
+		return err
+	}
+	committed = true
+	txCtx.RunAfterCommit()
+	return nil
+}
@@ -0,0 +1,121 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"net"
+)
+
+// mysql 错误码
+const (
+	mysqlErrDupEntry        = 1062
+	mysqlErrRowIsReferenced = 1451
+	mysqlErrNoReferencedRow = 1452
+)
+
+// postgres 错误码
+const (
+	pgErrUniqueViolation     = "23505"
+	pgErrForeignKeyViolation = "23503"
+)
+
+// sqlite 扩展错误码
+const (
+	sqliteErrConstraintForeignKey = 787
+	sqliteErrConstraintPrimaryKey = 1555
+	sqliteErrConstraintUnique     = 2067
+)
+
+// sqliteError sqlite 驱动返回的错误，通过错误码接口匹配，不依赖具体的驱动包
+type sqliteError interface {
+	error
+	Code() int
+}
+
+// gorm 的内部错误，说明sql语句或模型有误
+var gormErrors = []error{
+	gorm.ErrInvalidTransaction, gorm.ErrNotImplemented, gorm.ErrMissingWhereClause, gorm.ErrUnsupportedRelation,
+	gorm.ErrPrimaryKeyRequired, gorm.ErrModelValueRequired, gorm.ErrModelAccessibleFieldsRequired,
+	gorm.ErrSubQueryRequired, gorm.ErrInvalidData, gorm.ErrUnsupportedDriver, gorm.ErrRegistered,
+	gorm.ErrInvalidField, gorm.ErrEmptySlice, gorm.ErrDryRunModeUnsupported, gorm.ErrInvalidDB,
+	gorm.ErrInvalidValue, gorm.ErrInvalidValueOfLength, gorm.ErrPreloadNotAllowed, gorm.ErrCheckConstraintViolated,
+}
+
+// TranslateDBError 将数据库错误转换为业务异常，避免表名、sql等信息返回给客户端，原始错误只记录在日志中。
+// 非数据库错误原样返回
+func TranslateDBError(err error) error {
+
+	if err == nil {
+		return nil
+	}
+	var busErr *buserr.BusinessError
+	if errors.As(err, &busErr) {
+		return err
+	}
+
+	translated := translateDBError(err)
+	if translated == nil {
+		return err
+	}
+	zap.L().Error("数据库操作失败：", zap.Error(err))
+	return translated
+}
+
+func translateDBError(err error) error {
+
+	var mysqlErr *mysql.MySQLError
+	var pgErr *pgconn.PgError
+	var sqliteErr sqliteError
+	var netErr *net.OpError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, sql.ErrNoRows):
+		return buserr.ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return buserr.ErrDuplicateKey
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return buserr.ErrForeignKey
+	case errors.Is(err, context.DeadlineExceeded):
+		return buserr.ErrQueryTimeout
+	case errors.As(err, &mysqlErr):
+		switch mysqlErr.Number {
+		case mysqlErrDupEntry:
+			return buserr.ErrDuplicateKey
+		case mysqlErrRowIsReferenced, mysqlErrNoReferencedRow:
+			return buserr.ErrForeignKey
+		}
+		return buserr.ErrDatabase
+	case errors.As(err, &pgErr):
+		switch pgErr.Code {
+		case pgErrUniqueViolation:
+			return buserr.ErrDuplicateKey
+		case pgErrForeignKeyViolation:
+			return buserr.ErrForeignKey
+		}
+		return buserr.ErrDatabase
+	case errors.As(err, &sqliteErr):
+		switch sqliteErr.Code() {
+		case sqliteErrConstraintUnique, sqliteErrConstraintPrimaryKey:
+			return buserr.ErrDuplicateKey
+		case sqliteErrConstraintForeignKey:
+			return buserr.ErrForeignKey
+		}
+		return buserr.ErrDatabase
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.Is(err, sql.ErrTxDone),
+		errors.Is(err, mysql.ErrInvalidConn), errors.As(err, &netErr):
+		return buserr.ErrDatabase
+	}
+
+	for _, gormErr := range gormErrors {
+		if errors.Is(err, gormErr) {
+			return buserr.ErrDatabase
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"path/filepath"
+	"testing"
+)
+
+type constraintParent struct {
+	Id   uint64 `gorm:"primarykey"`
+	Code string `gorm:"uniqueIndex"`
+}
+
+type constraintChild struct {
+	Id       uint64 `gorm:"primarykey"`
+	ParentId uint64
+	Parent   constraintParent
+}
+
+func TestTranslateDBErrorSqliteConstraints(t *testing.T) {
+	for _, translateError := range []bool{false, true} {
+		t.Run(fmt.Sprintf("TranslateError=%v", translateError), func(t *testing.T) {
+			dsn := filepath.Join(t.TempDir(), "constraint.db") + "?_pragma=foreign_keys(1)"
+			db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard, TranslateError: translateError})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = db.AutoMigrate(&constraintParent{}, &constraintChild{}); err != nil {
+				t.Fatal(err)
+			}
+			parent := constraintParent{Code: "ops"}
+			if err = db.Create(&parent).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err = db.Create(&constraintChild{ParentId: parent.Id}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			tests := []struct {
+				name string
+				err  error
+				want error
+			}{
+				{"unique index", db.Create(&constraintParent{Code: "ops"}).Error, buserr.ErrDuplicateKey},
+				{"primary key", db.Create(&constraintParent{Id: parent.Id, Code: "dev"}).Error, buserr.ErrDuplicateKey},
+				{"missing parent", db.Create(&constraintChild{ParentId: 999}).Error, buserr.ErrForeignKey},
+				{"referenced parent", db.Delete(&parent).Error, buserr.ErrForeignKey},
+			}
+			for _, tt := range tests {
+				if tt.err == nil {
+					t.Fatalf("%s: expected a constraint error", tt.name)
+				}
+				if got := TranslateDBError(tt.err); got != tt.want {
+					t.Errorf("%s: TranslateDBError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTranslateDBErrorDriverCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"mysql duplicate entry", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'ops' for key 'sys_role.code'"}, buserr.ErrDuplicateKey},
+		{"mysql row is referenced", &mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row"}, buserr.ErrForeignKey},
+		{"mysql no referenced row", &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}, buserr.ErrForeignKey},
+		{"mysql other", &mysql.MySQLError{Number: 1146, Message: "Table 'gdmin.sys_role' doesn't exist"}, buserr.ErrDatabase},
+		{"postgres unique violation", &pgconn.PgError{Code: "23505"}, buserr.ErrDuplicateKey},
+		{"postgres foreign key violation", &pgconn.PgError{Code: "23503"}, buserr.ErrForeignKey},
+		{"postgres other", &pgconn.PgError{Code: "42P01"}, buserr.ErrDatabase},
+		{"wrapped", fmt.Errorf("create role: %w", &mysql.MySQLError{Number: 1062}), buserr.ErrDuplicateKey},
+		{"gorm duplicated key", gorm.ErrDuplicatedKey, buserr.ErrDuplicateKey},
+		{"record not found", gorm.ErrRecordNotFound, buserr.ErrNotFound},
+		{"business error", buserr.ErrRoleNotFound, buserr.ErrRoleNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TranslateDBError(tt.err); got != tt.want {
+				t.Fatalf("TranslateDBError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	// 非数据库错误原样返回
+	other := errors.New("other")
+	if got := TranslateDBError(other); got != other {
+		t.Fatalf("TranslateDBError(other) = %v, want the original error", got)
+	}
+}
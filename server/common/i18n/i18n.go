@@ -0,0 +1,139 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale 默认语言，请求的语言不受支持或缺少翻译时使用
+const DefaultLocale = "zh-CN"
+
+type localeKey struct{}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[string]map[string]string{}
+)
+
+// Register 注册语言的消息翻译，key 为业务错误码或消息标识，消息可包含 fmt 格式化占位符，
+// 重复注册同一语言时合并，相同 key 的消息会被覆盖，可用于新增语言或覆盖内置的翻译
+func Register(locale string, messages map[string]string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	locale = canonicalLocale(locale)
+	if catalog[locale] == nil {
+		catalog[locale] = make(map[string]string, len(messages))
+	}
+	for key, message := range messages {
+		catalog[locale][key] = message
+	}
+}
+
+// Translate 翻译消息，指定语言缺少翻译时使用默认语言，都不存在时返回 false
+func Translate(locale, key string, args ...any) (string, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	message, ok := catalog[canonicalLocale(locale)][key]
+	if !ok {
+		if message, ok = catalog[DefaultLocale][key]; !ok {
+			return "", false
+		}
+	}
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	return message, true
+}
+
+// MatchLocale 根据 Accept-Language 请求头选择已注册的语言，按权重依次匹配完整的语言标签及主语言，
+// 如 en-US 可匹配 en，zh 可匹配 zh-CN，都不匹配时使用默认语言
+func MatchLocale(acceptLanguage string) string {
+
+	type weightedTag struct {
+		tag    string
+		weight float64
+	}
+	var tags []weightedTag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if w, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = w
+			}
+		}
+		if weight > 0 {
+			tags = append(tags, weightedTag{tag, weight})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].weight > tags[j].weight })
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	for _, t := range tags {
+		tag := canonicalLocale(t.tag)
+		if _, ok := catalog[tag]; ok {
+			return tag
+		}
+		// 匹配主语言相同的语言，优先使用默认语言
+		base, _, _ := strings.Cut(tag, "-")
+		if defaultBase, _, _ := strings.Cut(DefaultLocale, "-"); base == defaultBase {
+			return DefaultLocale
+		}
+		for _, locale := range sortedLocales() {
+			if localeBase, _, _ := strings.Cut(locale, "-"); localeBase == base {
+				return locale
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Locales 已注册的语言
+func Locales() []string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	return sortedLocales()
+}
+
+func sortedLocales() []string {
+	locales := make([]string, 0, len(catalog))
+	for locale := range catalog {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// WithLocale 将请求的语言放入上下文
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFrom 获取上下文中的语言，不存在时返回默认语言
+func LocaleFrom(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// canonicalLocale 统一语言标签的格式，如 zh_cn 转换为 zh-CN
+func canonicalLocale(locale string) string {
+	base, region, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	base = strings.ToLower(base)
+	if !found {
+		return base
+	}
+	return base + "-" + strings.ToUpper(region)
+}
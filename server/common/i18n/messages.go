@@ -0,0 +1,116 @@
+package i18n
+
+// 消息标识，业务错误直接使用业务错误码作为 key
+const (
+	InternalError = "internal.error"
+
+	ParamRequired = "param.required"
+	ParamInvalid  = "param.invalid"
+
+	RoleInUseCount = "role.inUse.count"
+
+	RoleNameRequired    = "role.name.required"
+	RoleNameTooLong     = "role.name.tooLong"
+	RoleNameExists      = "role.name.exists"
+	RoleCodeRequired    = "role.code.required"
+	RoleCodeTooLong     = "role.code.tooLong"
+	RoleCodeInvalidChar = "role.code.invalidChar"
+	RoleCodeExists      = "role.code.exists"
+	RoleDescTooLong     = "role.desc.tooLong"
+
+	RoleDataScopeInvalid = "role.dataScope.invalid"
+
+	RoleSwapSelf = "role.swap.self"
+
+	RoleImportColumnMissing = "role.import.columnMissing"
+	RoleImportNameRepeated  = "role.import.nameRepeated"
+	RoleImportCodeRepeated  = "role.import.codeRepeated"
+)
+
+// 内置的中文及英文翻译，部署时可通过 Register 新增语言
+func init() {
+
+	Register("zh-CN", map[string]string{
+		"20001": "请求参数错误！",
+		"20002": "数据已被他人修改，请刷新后重试！",
+		"20003": "权限不足，请联系管理员分配权限！",
+		"20004": "数据不存在！",
+		"20005": "数据已存在，请勿重复添加！",
+		"20006": "数据存在关联，无法操作！",
+		"20007": "数据库操作超时，请稍后重试！",
+		"20008": "数据库操作失败，请稍后重试！",
+		"20009": "分页游标无效，请从第一页重新查询！",
+
+		"21001": "该角色不存在！",
+		"21002": "内置超级管理员角色不能删除或修改编码！",
+		"21003": "角色继承关系存在循环！",
+		"21004": "角色标识已存在！",
+		"21005": "该角色已分配给用户，不能删除！",
+		"21006": "角色的历史版本不存在！",
+
+		InternalError: "服务器内部错误，请稍后重试！",
+
+		ParamRequired: "参数%s不能为空",
+		ParamInvalid:  "参数%s不满足校验规则：%s",
+
+		RoleInUseCount:      "该角色已分配给%d个用户，不能删除！",
+		RoleNameRequired:    "角色名称不能为空",
+		RoleNameTooLong:     "角色名称长度不能超过%d",
+		RoleNameExists:      "角色名称已存在",
+		RoleCodeRequired:    "角色编码不能为空",
+		RoleCodeTooLong:     "角色编码长度不能超过%d",
+		RoleCodeInvalidChar: "角色编码只能包含字母、数字、下划线、冒号和中划线",
+		RoleCodeExists:      "角色编码已存在",
+		RoleDescTooLong:     "角色备注长度不能超过%d",
+
+		RoleDataScopeInvalid: "数据权限范围只能为%d到%d",
+
+		RoleSwapSelf: "不能与自身交换角色编码",
+
+		RoleImportColumnMissing: "缺少角色名称或编码",
+		RoleImportNameRepeated:  "文件中角色名称重复",
+		RoleImportCodeRepeated:  "文件中角色编码重复",
+	})
+
+	Register("en", map[string]string{
+		"20001": "Invalid request parameters",
+		"20002": "The data has been modified by someone else, please refresh and try again",
+		"20003": "Permission denied, please contact the administrator",
+		"20004": "The data does not exist",
+		"20005": "The data already exists",
+		"20006": "The data is referenced by other data and cannot be changed",
+		"20007": "The database operation timed out, please try again later",
+		"20008": "The database operation failed, please try again later",
+		"20009": "Invalid page cursor, please query from the first page",
+
+		"21001": "Role not found",
+		"21002": "The built-in super admin role cannot be deleted or have its code changed",
+		"21003": "The role inheritance contains a cycle",
+		"21004": "Role code already exists",
+		"21005": "The role is assigned to users and cannot be deleted",
+		"21006": "The role revision does not exist",
+
+		InternalError: "Internal server error, please try again later",
+
+		ParamRequired: "Parameter %s is required",
+		ParamInvalid:  "Parameter %s does not satisfy the rule: %s",
+
+		RoleInUseCount:      "The role is assigned to %d users and cannot be deleted",
+		RoleNameRequired:    "Role name is required",
+		RoleNameTooLong:     "Role name must not exceed %d characters",
+		RoleNameExists:      "Role name already exists",
+		RoleCodeRequired:    "Role code is required",
+		RoleCodeTooLong:     "Role code must not exceed %d characters",
+		RoleCodeInvalidChar: "Role code may only contain letters, digits, underscores, colons and hyphens",
+		RoleCodeExists:      "Role code already exists",
+		RoleDescTooLong:     "Role description must not exceed %d characters",
+
+		RoleDataScopeInvalid: "Data scope must be between %d and %d",
+
+		RoleSwapSelf: "A role cannot swap codes with itself",
+
+		RoleImportColumnMissing: "Missing role name or code",
+		RoleImportNameRepeated:  "Duplicate role name in the file",
+		RoleImportCodeRepeated:  "Duplicate role code in the file",
+	})
+}
@@ -10,7 +10,9 @@ type JWTClaims struct {
 
 // UserClaims user claims
 type UserClaims struct {
-	ID       uint64
-	Username string
-	NickName string
+	ID           uint64
+	Username     string
+	NickName     string
+	RoleCodes    []string // 用户拥有的已启用角色编码
+	TokenVersion int      // 签发时用户的令牌版本号，用户角色变更后旧令牌失效
 }
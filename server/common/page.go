@@ -1,5 +1,17 @@
 package common
 
+import (
+	"encoding/base64"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"strconv"
+)
+
+const (
+	// 游标分页默认及最大的每页数量
+	defaultCursorLimit = 10
+	maxCursorLimit     = 100
+)
+
 // PageReq 分页请求参数
 type PageReq struct {
 	Current int `json:"current"`
@@ -28,3 +40,52 @@ func (req *PageReq) InitDefaultValue() {
 	req.Limit = req.Size
 	req.Offset = req.Size * (req.Current - 1)
 }
+
+// CursorReq 游标分页请求参数，按id升序分页，适用于数据量大的列表，深分页时比 offset 分页快。
+// Cursor 为上一页返回的 NextCursor，为空时查询第一页
+type CursorReq struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+// CursorResp 游标分页返回参数，NextCursor 为空表示没有更多数据
+type CursorResp struct {
+	Records    any    `json:"records"`
+	NextCursor string `json:"nextCursor"`
+}
+
+// InitDefaultValue 初始化默认值
+func (req *CursorReq) InitDefaultValue() {
+	if req.Limit <= 0 {
+		req.Limit = defaultCursorLimit
+	}
+	if req.Limit > maxCursorLimit {
+		req.Limit = maxCursorLimit
+	}
+}
+
+// AfterId 解析游标得到上一页最后一条数据的id，游标为空时返回0
+func (req *CursorReq) AfterId() (uint64, error) {
+	if req.Cursor == "" {
+		return 0, nil
+	}
+	return DecodeCursor(req.Cursor)
+}
+
+// EncodeCursor 将id编码为游标
+func EncodeCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+// DecodeCursor 解析游标，游标被篡改或格式错误时返回 ErrInvalidCursor
+func DecodeCursor(cursor string) (uint64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, buserr.ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil || id == 0 {
+		return 0, buserr.ErrInvalidCursor
+	}
+	return id, nil
+}
@@ -10,6 +10,8 @@ const (
 	BindModeBody BindMode = iota
 	// BindModeQuery 表示查询参数绑定
 	BindModeQuery
+	// BindModeUri 表示路径参数绑定
+	BindModeUri
 )
 
 // Request is the base struct for all requests.
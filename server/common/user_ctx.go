@@ -28,6 +28,15 @@ func (*userContext) GetUserClaims(c *gin.Context) (*UserClaims, error) {
 	}
 }
 
+// GetClaims 获取上下文中的登录用户，没有登录用户时返回 nil
+func (*userContext) GetClaims(ctx context.Context) *UserClaims {
+
+	if claims, ok := ctx.Value(ClaimsKey).(*UserClaims); ok {
+		return claims
+	}
+	return nil
+}
+
 func (*userContext) GetUserId(ctx *context.Context) uint64 {
 
 	if claims := (*ctx).Value(ClaimsKey); claims != nil {
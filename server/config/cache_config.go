@@ -0,0 +1,6 @@
+package config
+
+type Cache struct {
+	RoleTTL        int64 `mapstructure:"role-ttl"`        // 角色权限缓存的过期时间，单位：秒
+	IdempotencyTTL int64 `mapstructure:"idempotency-ttl"` // 幂等键的有效期，单位：秒
+}
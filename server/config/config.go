@@ -5,5 +5,9 @@ type Config struct {
 	Gin
 	Database
 	Redis
+	Cache
+	Role
+	Webhook
+	RateLimit `mapstructure:"rate-limit"`
 	Zap
 }
@@ -1,8 +1,19 @@
 package config
 
 type Database struct {
-	Driver string
-	Mysql  Mysql
+	Driver       string // 数据库类型，支持 mysql 和 postgres
+	AutoMigrate  bool   `mapstructure:"auto-migrate"`  // 启动时是否自动迁移表结构
+	QueryTimeout int64  `mapstructure:"query-timeout"` // 默认的sql执行超时时间（毫秒），上下文没有截止时间时生效，0表示不限制
+	Mysql        Mysql
+	Postgres     Postgres
+}
+
+// TablePrefix 当前数据库类型配置的表前缀
+func (d *Database) TablePrefix() string {
+	if d.Driver == "postgres" {
+		return d.Postgres.TablePrefix
+	}
+	return d.Mysql.TablePrefix
 }
 
 type Mysql struct {
@@ -12,3 +23,11 @@ type Mysql struct {
 	MaxIdleCount  int    `mapstructure:"max-idle-count"` // 最大空闲连接数
 	MaxOpenConns  int    `mapstructure:"max-open-conns"` // 最大打开连接数
 }
+
+type Postgres struct {
+	DSN           string
+	TablePrefix   string `mapstructure:"table-prefix"`   // 表前缀
+	SingularTable bool   `mapstructure:"singular-table"` // 是否使用单数表名
+	MaxIdleCount  int    `mapstructure:"max-idle-count"` // 最大空闲连接数
+	MaxOpenConns  int    `mapstructure:"max-open-conns"` // 最大打开连接数
+}
@@ -0,0 +1,11 @@
+package config
+
+type RateLimit struct {
+	Store  string           // 令牌桶存储，memory：单机内存，redis：多实例共享
+	Groups map[string]Limit // 各路由组的限流配置，未配置的路由组不限流
+}
+
+type Limit struct {
+	Rate  float64 // 每秒允许的请求数
+	Burst int     // 允许的突发请求数
+}
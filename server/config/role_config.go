@@ -0,0 +1,5 @@
+package config
+
+type Role struct {
+	ReservedCodePrefixes []string `mapstructure:"reserved-code-prefixes"` // 保留的角色编码前缀，编码以这些前缀开头的角色只有超级管理员可以管理
+}
@@ -3,4 +3,6 @@ package config
 type Gin struct {
 	Address string // 服务监听地址
 	Mode    string // gin运行模式 debug/test/release
+
+	ShutdownTimeout int64 `mapstructure:"shutdown-timeout"` // 优雅停机时等待进行中请求完成的最长时间，单位：秒
 }
@@ -0,0 +1,9 @@
+package config
+
+type Webhook struct {
+	URLs        []string `mapstructure:"urls"`         // 接收角色变更通知的地址，为空时不发送
+	Secret      string   `mapstructure:"secret"`       // 签名密钥，请求体的 HMAC-SHA256 签名放在 X-Signature 请求头
+	Timeout     int64    `mapstructure:"timeout"`      // 单次请求的超时时间，单位：毫秒
+	MaxAttempts int      `mapstructure:"max-attempts"` // 最大发送次数，包括首次发送
+	Backoff     int64    `mapstructure:"backoff"`      // 首次重试的等待时间，之后每次翻倍，单位：毫秒
+}
@@ -0,0 +1,157 @@
+package event
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Bus 全局事件总线
+var Bus = NewEventBus()
+
+// Event 领域事件
+type Event interface {
+	// Topic 事件主题
+	Topic() string
+}
+
+// Subscriber 事件订阅者，返回的错误只记录日志，不影响事件的发布方
+type Subscriber interface {
+	Handle(ctx context.Context, e Event) error
+}
+
+// SubscriberFunc 函数形式的事件订阅者
+type SubscriberFunc func(ctx context.Context, e Event) error
+
+func (f SubscriberFunc) Handle(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+// EventBus 进程内的事件总线，订阅者异步处理事件，不保证事件的处理顺序
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []Subscriber
+	closed      bool
+
+	wg     sync.WaitGroup  // 进行中的订阅者
+	ctx    context.Context // 关闭超时后取消，中止进行中的订阅者
+	cancel context.CancelFunc
+}
+
+func NewEventBus() *EventBus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EventBus{ctx: ctx, cancel: cancel}
+}
+
+// Subscribe 添加事件订阅者，订阅者会收到所有主题的事件
+func (b *EventBus) Subscribe(subscriber Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+// Publish 发布事件，请求的上下文取消后订阅者仍可继续处理，事件总线关闭后发布的事件只记录日志
+func (b *EventBus) Publish(ctx context.Context, e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		zap.L().Warn("事件总线已关闭，丢弃事件", zap.String("topic", e.Topic()))
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, subscriber := range b.subscribers {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+
+			// 事件总线关闭超时后取消订阅者的上下文
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			defer context.AfterFunc(b.ctx, cancel)()
+
+			dispatch(ctx, subscriber, e)
+		}()
+	}
+}
+
+// Close 停止接收新的事件，并等待进行中的订阅者处理完成，如 webhook 的重试。
+// 上下文取消时不再等待，取消订阅者的上下文并返回上下文的错误
+func (b *EventBus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+func dispatch(ctx context.Context, subscriber Subscriber, e Event) {
+	defer func() {
+		if err := recover(); err != nil {
+			zap.L().Error("事件订阅者处理异常：", zap.String("topic", e.Topic()), zap.Any("error", err))
+		}
+	}()
+
+	if err := subscriber.Handle(ctx, e); err != nil {
+		zap.L().Error("事件订阅者处理失败：", zap.String("topic", e.Topic()), zap.Error(err))
+	}
+}
+
+// RoleEvent 角色变更事件
+type RoleEvent struct {
+	RoleId uint64    `json:"roleId"` // 角色id
+	Code   string    `json:"code"`   // 角色编码，删除事件为删除前的编码
+	Name   string    `json:"name"`   // 角色名称
+	By     uint64    `json:"by"`     // 操作人id
+	At     time.Time `json:"at"`     // 操作时间
+}
+
+// RoleCreated 角色新增事件
+type RoleCreated struct {
+	RoleEvent
+}
+
+func (RoleCreated) Topic() string {
+	return "role.created"
+}
+
+// RoleUpdated 角色修改事件
+type RoleUpdated struct {
+	RoleEvent
+}
+
+func (RoleUpdated) Topic() string {
+	return "role.updated"
+}
+
+// RoleDeleted 角色删除事件
+type RoleDeleted struct {
+	RoleEvent
+}
+
+func (RoleDeleted) Topic() string {
+	return "role.deleted"
+}
+
+// RoleRestored 已删除的角色恢复事件
+type RoleRestored struct {
+	RoleEvent
+}
+
+func (RoleRestored) Topic() string {
+	return "role.restored"
+}
@@ -0,0 +1,119 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testEvent struct{}
+
+func (testEvent) Topic() string {
+	return "test"
+}
+
+func TestEventBusCloseWaitsForSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	var handled atomic.Int32
+	bus.Subscribe(SubscriberFunc(func(ctx context.Context, e Event) error {
+		time.Sleep(50 * time.Millisecond)
+		handled.Add(1)
+		return nil
+	}))
+
+	// 请求的上下文取消不影响订阅者的处理
+	reqCtx, cancel := context.WithCancel(context.Background())
+	bus.Publish(reqCtx, testEvent{})
+	bus.Publish(reqCtx, testEvent{})
+	cancel()
+
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := handled.Load(); got != 2 {
+		t.Fatalf("handled events = %d after Close, want 2", got)
+	}
+
+	// 关闭后发布的事件不再处理
+	bus.Publish(context.Background(), testEvent{})
+	time.Sleep(80 * time.Millisecond)
+	if got := handled.Load(); got != 2 {
+		t.Fatalf("handled events = %d after publishing to a closed bus, want 2", got)
+	}
+}
+
+func TestEventBusCloseTimeoutCancelsSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	canceled := make(chan struct{})
+	bus.Subscribe(SubscriberFunc(func(ctx context.Context, e Event) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}))
+	bus.Publish(context.Background(), testEvent{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close = %v, want DeadlineExceeded", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("subscriber context not canceled after the close timeout")
+	}
+}
+
+func TestEventBusCloseWaitsForWebhookRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次请求失败，重试后成功
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bus := NewEventBus()
+	bus.Subscribe(NewWebhookSubscriber([]string{server.URL}, "secret", time.Second, 3, 50*time.Millisecond))
+	bus.Publish(context.Background(), RoleCreated{RoleEvent{RoleId: 1, Code: "ops"}})
+
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("webhook attempts = %d after Close, want 2", got)
+	}
+}
+
+func TestEventBusCloseTimeoutStopsWebhookRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	bus := NewEventBus()
+	bus.Subscribe(NewWebhookSubscriber([]string{server.URL}, "secret", time.Second, 5, time.Hour))
+	bus.Publish(context.Background(), RoleCreated{RoleEvent{RoleId: 1, Code: "ops"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Close took %v, want it to stop waiting at the timeout", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("webhook attempts = %d, want 1 before the close timeout", got)
+	}
+}
@@ -0,0 +1,14 @@
+package event
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+// LogSubscriber 将事件输出到日志的订阅者
+type LogSubscriber struct{}
+
+func (*LogSubscriber) Handle(_ context.Context, e Event) error {
+	zap.L().Info("领域事件："+e.Topic(), zap.Any("event", e))
+	return nil
+}
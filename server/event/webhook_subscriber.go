@@ -0,0 +1,158 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SignatureHeader webhook 请求体签名的请求头，值为十六进制的 HMAC-SHA256 签名
+const SignatureHeader = "X-Signature"
+
+// roleEvent 角色变更事件，由嵌入的 RoleEvent 实现
+type roleEvent interface {
+	roleEvent() RoleEvent
+}
+
+func (e RoleEvent) roleEvent() RoleEvent {
+	return e
+}
+
+// WebhookPayload webhook 通知的请求体
+type WebhookPayload struct {
+	Event     string    `json:"event"`     // 事件类型，如 role.created
+	RoleId    uint64    `json:"roleId"`    // 角色id
+	Code      string    `json:"code"`      // 角色编码
+	Name      string    `json:"name"`      // 角色名称
+	Actor     uint64    `json:"actor"`     // 操作人id
+	Timestamp time.Time `json:"timestamp"` // 操作时间
+}
+
+// WebhookSubscriber 将角色变更事件以 json 格式 POST 到配置的地址，失败时按指数退避重试，
+// 各地址并发发送，单次请求有超时时间，最终发送失败只记录日志。上下文取消（如停机超时）时不再重试
+type WebhookSubscriber struct {
+	urls        []string
+	secret      []byte
+	maxAttempts int
+	backoff     time.Duration
+	client      *http.Client
+}
+
+// NewWebhookSubscriber 创建 webhook 订阅者，超时时间、发送次数、重试等待时间未配置时分别默认为 3s、3次、500ms
+func NewWebhookSubscriber(urls []string, secret string, timeout time.Duration, maxAttempts int, backoff time.Duration) *WebhookSubscriber {
+
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+
+	return &WebhookSubscriber{
+		urls:        urls,
+		secret:      []byte(secret),
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		client:      &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *WebhookSubscriber) Handle(ctx context.Context, e Event) error {
+
+	re, ok := e.(roleEvent)
+	if !ok {
+		return nil
+	}
+	role := re.roleEvent()
+	body, err := json.Marshal(WebhookPayload{
+		Event: e.Topic(), RoleId: role.RoleId, Code: role.Code, Name: role.Name, Actor: role.By, Timestamp: role.At,
+	})
+	if err != nil {
+		return err
+	}
+	signature := Sign(s.secret, body)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(s.urls))
+	for i := range s.urls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.deliver(ctx, s.urls[i], e.Topic(), body, signature)
+		}(i)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// deliver 发送到一个地址，网络错误、429及5xx响应会重试，其他非2xx响应不重试
+func (s *WebhookSubscriber) deliver(ctx context.Context, url, topic string, body []byte, signature string) error {
+
+	var err error
+	wait := s.backoff
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		var retryable bool
+		if retryable, err = s.post(ctx, url, topic, body, signature); err == nil || !retryable {
+			break
+		}
+		if attempt == s.maxAttempts {
+			break
+		}
+
+		zap.L().Warn("webhook 发送失败，等待重试", zap.String("url", url), zap.Int("attempt", attempt), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", url, err)
+	}
+	return nil
+}
+
+func (s *WebhookSubscriber) post(ctx context.Context, url, topic string, body []byte, signature string) (retryable bool, err error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event", topic)
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// Sign 计算请求体的 HMAC-SHA256 签名，接收方使用相同的密钥计算后与 X-Signature 请求头比较
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -19,7 +19,8 @@ func InitCasbin() {
 		os.Exit(1)
 	}
 
-	dbAdapter, err := gormAdapter.NewAdapterByDB(global.GormDB)
+	// 策略表与业务表使用相同的表前缀，多个租户共用一个数据库时互不影响
+	dbAdapter, err := gormAdapter.NewAdapterByDBUseTableName(global.GormDB, "", global.Config.Database.TablePrefix()+"casbin_rule")
 	if err != nil {
 		zap.L().Error("连接数据库失败：", zap.Error(err))
 		os.Exit(1)
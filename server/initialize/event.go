@@ -0,0 +1,18 @@
+package initialize
+
+import (
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/global"
+	"time"
+)
+
+// InitEvent 注册事件订阅者
+func InitEvent() {
+	event.Bus.Subscribe(&event.LogSubscriber{})
+
+	// 配置了通知地址时，角色变更后发送 webhook 通知
+	if w := global.Config.Webhook; len(w.URLs) > 0 {
+		event.Bus.Subscribe(event.NewWebhookSubscriber(w.URLs, w.Secret,
+			time.Duration(w.Timeout)*time.Millisecond, w.MaxAttempts, time.Duration(w.Backoff)*time.Millisecond))
+	}
+}
@@ -3,6 +3,7 @@ package initialize
 import (
 	"gitee.com/nichanghao/gdmin/global"
 	"gitee.com/nichanghao/gdmin/middleware"
+	"gitee.com/nichanghao/gdmin/web/openapi"
 	"gitee.com/nichanghao/gdmin/web/router"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -12,6 +13,8 @@ func InitGin() *gin.Engine {
 
 	gin.SetMode(global.Config.Gin.Mode)
 	engine := gin.New()
+	// 响应信息的语言
+	engine.Use(middleware.LocaleHandler())
 	engine.Use(middleware.GlobalErrorHandler())
 	engine.Use(middleware.GinZapLogger(zap.L()))
 	// panic 处理
@@ -26,14 +29,21 @@ func InitGin() *gin.Engine {
 	//自有路由组，只要jwt鉴权即可
 	selfGroup := engine.Group("")
 	selfGroup.Use(middleware.JwtAuthHandler())
+	selfGroup.Use(middleware.RateLimitHandler("private"))
 	router.Self.InitRouter(selfGroup)
 
 	// 私有路由组，需要jwt鉴权和casbin权限控制
 	privateGroup := engine.Group("")
 	privateGroup.Use(middleware.JwtAuthHandler())
+	privateGroup.Use(middleware.RateLimitHandler("private"))
 	privateGroup.Use(middleware.CasbinAuthHandler())
 	router.Private.InitRouter(privateGroup)
 
+	// 根据已注册的路由生成接口文档
+	if err := openapi.Init(engine.Routes()); err != nil {
+		zap.L().Error("Init openapi error: ", zap.Error(err))
+	}
+
 	return engine
 
 }
@@ -5,6 +5,7 @@ import (
 	"gitee.com/nichanghao/gdmin/initialize/_logger"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 	"log"
@@ -16,6 +17,8 @@ func InitGorm() *gorm.DB {
 	switch global.Config.Database.Driver {
 	case "mysql":
 		return initGormMysql()
+	case "postgres":
+		return initGormPostgres()
 	default:
 		return initGormMysql()
 	}
@@ -31,19 +34,41 @@ func initGormMysql() *gorm.DB {
 		SkipInitializeWithVersion: true,
 	}
 
-	if db, err := gorm.Open(mysql.New(mysqlConfig), &gorm.Config{
+	return openGorm(mysql.New(mysqlConfig), m.TablePrefix, m.SingularTable, m.MaxIdleCount, m.MaxOpenConns)
+}
+
+func initGormPostgres() *gorm.DB {
+	p := global.Config.Database.Postgres
+
+	postgresConfig := postgres.Config{
+		DSN: p.DSN,
+	}
+
+	return openGorm(postgres.New(postgresConfig), p.TablePrefix, p.SingularTable, p.MaxIdleCount, p.MaxOpenConns)
+}
+
+func openGorm(dialector gorm.Dialector, tablePrefix string, singularTable bool, maxIdleCount, maxOpenConns int) *gorm.DB {
+
+	if db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: _logger.NewZapGormLogger(zap.L(), 200*time.Millisecond),
 		NamingStrategy: schema.NamingStrategy{
-			TablePrefix: m.TablePrefix,
+			TablePrefix: tablePrefix,
 			// 单数表名
-			SingularTable: m.SingularTable,
-		}}); err != nil {
+			SingularTable: singularTable,
+		},
+		// 由驱动将唯一键、外键冲突转换为 gorm 的通用错误
+		TranslateError: true,
+	}); err != nil {
 		log.Fatalf("failed to connect database, the error is %v", err)
 		return nil
 	} else {
 		s, _ := db.DB()
-		s.SetMaxIdleConns(m.MaxIdleCount)
-		s.SetMaxOpenConns(m.MaxOpenConns)
+		s.SetMaxIdleConns(maxIdleCount)
+		s.SetMaxOpenConns(maxOpenConns)
+
+		if err = registerQueryTimeout(db, time.Duration(global.Config.Database.QueryTimeout)*time.Millisecond); err != nil {
+			log.Fatalf("failed to register query timeout, the error is %v", err)
+		}
 
 		return db
 	}
@@ -0,0 +1,57 @@
+package initialize
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const queryTimeoutKey = "gdmin:query_timeout"
+
+// queryTimeout 记录设置超时前的上下文，执行结束后恢复，避免复用同一个Statement时使用已取消的上下文
+type queryTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// registerQueryTimeout 注册gorm回调，上下文没有截止时间时为sql设置默认的超时时间。
+// Row/Rows 的结果集在回调结束后才读取，取消上下文会导致读取失败，因此不设置超时时间
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+
+	if timeout <= 0 {
+		return nil
+	}
+
+	before := func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if _, ok := ctx.Deadline(); ok {
+			return
+		}
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		tx.Statement.Context = timeoutCtx
+		tx.InstanceSet(queryTimeoutKey, &queryTimeout{parent: ctx, cancel: cancel})
+	}
+	after := func(tx *gorm.DB) {
+		if v, ok := tx.InstanceGet(queryTimeoutKey); ok && v != nil {
+			t := v.(*queryTimeout)
+			t.cancel()
+			tx.Statement.Context = t.parent
+			tx.InstanceSet(queryTimeoutKey, nil)
+		}
+	}
+
+	callback := db.Callback()
+	return errors.Join(
+		callback.Query().Before("gorm:query").Register("gdmin:query_timeout_before", before),
+		callback.Query().After("gorm:after_query").Register("gdmin:query_timeout_after", after),
+		callback.Create().Before("gorm:begin_transaction").Register("gdmin:query_timeout_before", before),
+		callback.Create().After("gorm:commit_or_rollback_transaction").Register("gdmin:query_timeout_after", after),
+		callback.Update().Before("gorm:begin_transaction").Register("gdmin:query_timeout_before", before),
+		callback.Update().After("gorm:commit_or_rollback_transaction").Register("gdmin:query_timeout_after", after),
+		callback.Delete().Before("gorm:begin_transaction").Register("gdmin:query_timeout_before", before),
+		callback.Delete().After("gorm:commit_or_rollback_transaction").Register("gdmin:query_timeout_after", after),
+		callback.Raw().Before("gorm:raw").Register("gdmin:query_timeout_before", before),
+		callback.Raw().After("gorm:raw").Register("gdmin:query_timeout_after", after),
+	)
+}
@@ -13,12 +13,24 @@ func init() {
 	gorm := InitGorm()
 	global.GormDB = gorm
 
+	// 自动迁移表结构
+	InitMigrate()
+
 	// 初始化 casbin
 	InitCasbin()
 
 	// 初始化 redis
 	InitRedis()
 
+	// 初始化角色管理的授权策略
+	InitPolicy()
+
+	// 注册事件订阅者
+	InitEvent()
+
+	// 注册监控指标
+	InitMetrics()
+
 	//初始化gin
 	engine := InitGin()
 	global.GinEngine = engine
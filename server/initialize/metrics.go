@@ -0,0 +1,11 @@
+package initialize
+
+import (
+	"gitee.com/nichanghao/gdmin/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// InitMetrics 注册 Prometheus 监控指标
+func InitMetrics() {
+	prometheus.MustRegister(metrics.Role)
+}
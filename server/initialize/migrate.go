@@ -0,0 +1,32 @@
+package initialize
+
+import (
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/model"
+	"go.uber.org/zap"
+	"os"
+)
+
+// InitMigrate 根据模型自动迁移表结构，字段类型和注释由gorm按数据库类型生成
+func InitMigrate() {
+
+	if !global.Config.Database.AutoMigrate {
+		return
+	}
+
+	err := global.GormDB.AutoMigrate(
+		&model.SysUser{},
+		&model.SysRole{},
+		&model.SysMenu{},
+		&model.SysDept{},
+		&model.SysUserRole{},
+		&model.SysRoleMenu{},
+		&model.SysRoleDept{},
+		&model.SysOperationLog{},
+		&model.SysRoleHistory{},
+	)
+	if err != nil {
+		zap.L().Error("自动迁移表结构失败：", zap.Error(err))
+		os.Exit(1)
+	}
+}
@@ -28,10 +28,35 @@ func init() {
 
 	{
 		addPermissionRouter(controller.SysRole.PageRoles, "sys:role")
+		addPermissionRouter(controller.SysRole.CursorRoles, "sys:role")
 		addPermissionRouter(controller.SysRole.AddRole, "sys:role:add")
+		addPermissionRouter(controller.SysRole.CloneRole, "sys:role:add")
 		addPermissionRouter(controller.SysRole.EditRole, "sys:role:edit")
+		addPermissionRouter(controller.SysRole.PatchRole, "sys:role:edit")
+		addPermissionRouter(controller.SysRole.SwapRoleCodes, "sys:role:edit")
 		addPermissionRouter(controller.SysRole.DeleteRole, "sys:role:delete")
+		addPermissionRouter(controller.SysRole.DeleteRoles, "sys:role:delete")
 		addPermissionRouter(controller.SysRole.AssignRoleMenus, "sys:role:assignMenus")
+		addPermissionRouter(controller.SysRole.PageDeletedRoles, "sys:role:restore")
+		addPermissionRouter(controller.SysRole.RestoreRole, "sys:role:restore")
+		addPermissionRouter(controller.SysRole.RepairRoleJoins, "sys:role:repair")
+		addPermissionRouter(controller.SysRole.ImportRoles, "sys:role:import")
+		addPermissionRouter(controller.SysRole.ImportRolesJson, "sys:role:import")
+		addPermissionRouter(controller.SysRole.ExportRoles, "sys:role:export")
+		addPermissionRouter(controller.SysRole.GetRoleTree, "sys:role")
+		addPermissionRouter(controller.SysRole.GetEffectiveMenuIds, "sys:role")
+		addPermissionRouter(controller.SysRole.PageRoleUsers, "sys:role")
+		addPermissionRouter(controller.SysRole.CursorRoleUsers, "sys:role")
+		addPermissionRouter(controller.SysRole.PageRolesWithUserCount, "sys:role")
+		addPermissionRouter(controller.SysRole.GetRole, "sys:role")
+		addPermissionRouter(controller.SysRole.ListRolesByUser, "sys:role")
+		addPermissionRouter(controller.SysRole.ListRolesByMenu, "sys:role")
+		addPermissionRouter(controller.SysRole.SearchRoles, "sys:role")
+		addPermissionRouter(controller.SysRole.AddRoleUsers, "sys:role:assignUsers")
+		addPermissionRouter(controller.SysRole.RemoveRoleUsers, "sys:role:assignUsers")
+		addPermissionRouter(controller.SysAudit.ListRoleLogs, "sys:role:audit")
+		addPermissionRouter(controller.SysAudit.ListRoleRevisions, "sys:role:audit")
+		addPermissionRouter(controller.SysAudit.GetRoleRevision, "sys:role:audit")
 	}
 
 }
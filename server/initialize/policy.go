@@ -0,0 +1,14 @@
+package initialize
+
+import (
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/service/system"
+)
+
+// InitPolicy 初始化角色管理的授权策略，未配置保留的角色编码前缀时使用默认策略
+func InitPolicy() {
+
+	if prefixes := global.Config.Role.ReservedCodePrefixes; len(prefixes) > 0 {
+		system.RoleService.SetAuthorizationPolicy(system.NewReservedCodePolicy(prefixes...))
+	}
+}
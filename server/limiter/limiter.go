@@ -0,0 +1,110 @@
+package limiter
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/global"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"sync"
+	"time"
+)
+
+// Store 令牌桶存储接口，单机部署使用内存存储，多实例部署使用redis存储
+type Store interface {
+	// Allow 从 key 对应的令牌桶中取出一个令牌，rate 为每秒生成的令牌数，burst 为桶的容量；
+	// 令牌不足时返回 false 及需要等待的时间
+	Allow(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+}
+
+// MemoryStore 基于内存的令牌桶存储
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// 内存中令牌桶数量超过该值时清理已装满的令牌桶
+const memoryStoreCleanupSize = 10000
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryStore) Allow(_ context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if len(s.buckets) > memoryStoreCleanupSize {
+		s.cleanup(now, rate, burst)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second)), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// cleanup 删除已装满的令牌桶，装满的令牌桶与新建的令牌桶等价
+func (s *MemoryStore) cleanup(now time.Time, rate float64, burst int) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= float64(burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RedisStore 基于redis的令牌桶存储，令牌的计算在lua脚本中完成，多实例共享同一个令牌桶
+type RedisStore struct{}
+
+func NewRedisStore() *RedisStore {
+	return &RedisStore{}
+}
+
+// KEYS[1]: 令牌桶key；ARGV: 每秒生成的令牌数，桶的容量，当前时间（毫秒）
+// 返回：是否允许（1/0），需要等待的时间（毫秒）
+var allowScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1]) or burst
+local last = tonumber(bucket[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - last) / 1000 * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+func (*RedisStore) Allow(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+
+	res, err := allowScript.Run(ctx, global.RedisCli, []string{key}, rate, burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
 	"gitee.com/nichanghao/gdmin/global"
 	_ "gitee.com/nichanghao/gdmin/initialize"
+	"gitee.com/nichanghao/gdmin/service"
 	"gitee.com/nichanghao/gdmin/web"
+	"log"
+	"os"
 )
 
 func main() {
+	// 初始化内置数据：gdmin seed
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := service.SysRole.Seed(context.Background()); err != nil {
+			log.Fatalf("Seed failed: %v", err)
+		}
+		log.Println("Seed completed")
+		return
+	}
+
 	// 启动web服务
 	web.StartServer(global.GinEngine)
 }
@@ -0,0 +1,69 @@
+// Package metrics Prometheus 监控指标
+package metrics
+
+import (
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+// 角色服务的操作类型，作为指标的 op 标签
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+	OpList   = "list"
+)
+
+// Role 角色服务的监控指标，由 initialize 注册
+var Role = NewRoleCollector()
+
+// RoleCollector 角色服务的监控指标，包括各操作的耗时分布及按错误码统计的业务错误数量
+type RoleCollector struct {
+	opDuration *prometheus.HistogramVec
+	busErrors  *prometheus.CounterVec
+}
+
+// NewRoleCollector 创建角色服务的监控指标
+func NewRoleCollector() *RoleCollector {
+	return &RoleCollector{
+		opDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "role_service_op_duration_seconds",
+			Help:    "Duration of role service operations in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"op"}),
+		busErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "role_service_business_errors_total",
+			Help: "Number of business errors returned by role service operations.",
+		}, []string{"op", "code"}),
+	}
+}
+
+func (c *RoleCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.opDuration.Describe(ch)
+	c.busErrors.Describe(ch)
+}
+
+func (c *RoleCollector) Collect(ch chan<- prometheus.Metric) {
+	c.opDuration.Collect(ch)
+	c.busErrors.Collect(ch)
+}
+
+// Observe 记录一次操作的耗时，err 为业务错误时按错误码计数，数据库错误按转换后的业务错误码计数
+func (c *RoleCollector) Observe(op string, start time.Time, err error) {
+	c.opDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if err == nil {
+		return
+	}
+
+	err = common.TranslateDBError(err)
+	var validErr *buserr.ValidationError
+	if errors.As(err, &validErr) {
+		c.busErrors.WithLabelValues(op, strconv.Itoa(buserr.ErrIllegalParameter.Code)).Inc()
+	} else if code, ok := buserr.CodeOf(err); ok {
+		c.busErrors.WithLabelValues(op, strconv.Itoa(code)).Inc()
+	}
+}
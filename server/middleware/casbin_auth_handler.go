@@ -8,7 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// CasbinAuthHandler 权限控制
+// CasbinAuthHandler 权限控制，根据路由处理函数注册的权限标识校验登录用户的权限，
+// 用户通过 casbin 中用户与角色的关联继承角色的权限，不依赖 token 中的角色编码，角色变更后立即生效
 func CasbinAuthHandler() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
@@ -28,7 +29,7 @@ func CasbinAuthHandler() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		if enforce, err2 := global.Enforcer.Enforce(service.SysCasbin.GetCasbinUserStr(userClaims.ID), permission); err2 != nil || !enforce {
+		if enforce, err2 := service.SysCasbin.Enforce(service.SysCasbin.GetCasbinUserStr(userClaims.ID), permission); err2 != nil || !enforce {
 			_ = c.Error(buserr.ErrPermissionDenied)
 			c.Abort()
 		} else {
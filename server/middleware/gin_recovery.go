@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"gitee.com/nichanghao/gdmin/web/response"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net"
-	"net/http"
 	"os"
 	"runtime"
 	"strings"
@@ -58,7 +58,7 @@ func GinRecovery(logger *zap.Logger) gin.HandlerFunc {
 					return
 				}
 
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Internal Server Error"})
+				response.FailWithPanic(c)
 			}
 		}()
 		c.Next()
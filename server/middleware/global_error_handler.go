@@ -2,9 +2,14 @@ package middleware
 
 import (
 	"errors"
+	"gitee.com/nichanghao/gdmin/common"
 	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
 	"gitee.com/nichanghao/gdmin/web/response"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"unicode"
 )
 
 // GlobalErrorHandler 定义一个全局的错误处理中间件
@@ -14,15 +19,25 @@ func GlobalErrorHandler() gin.HandlerFunc {
 		defer func() {
 			// 检查是否有错误发生
 			if len(c.Errors) > 0 {
-				err := c.Errors.Last()
+				// 数据库错误转换为业务错误，不向客户端暴露原始错误信息
+				err := common.TranslateDBError(c.Errors.Last().Err)
+				if c.Errors.Last().IsType(gin.ErrorTypeBind) {
+					err = bindError(err)
+				}
 
-				// 业务错误
-				var busErr *buserr.BusinessError
-				switch {
-				case errors.As(err.Err, &busErr):
-					response.FailWithBusErr(busErr, c)
+				// 业务错误，code码优先使用注册的业务错误码，错误信息按请求的语言翻译
+				locale := i18n.LocaleFrom(c.Request.Context())
+				var validErr *buserr.ValidationError
+				switch code, ok := buserr.CodeOf(err); {
+				case errors.As(err, &validErr):
+					response.FailWithValidationErr(validErr.Localize(locale), c)
+				case ok:
+					response.FailWithCode(code, buserr.Localize(err, locale), c)
 				default:
-					response.FailWithMessage(err.Error(), c)
+					// 未知错误只记录日志，向客户端返回通用的错误信息
+					zap.L().Error("请求处理失败：", zap.String("path", c.FullPath()), zap.Error(err))
+					message, _ := i18n.Translate(locale, i18n.InternalError)
+					response.FailWithInternalErr(message, c)
 				}
 
 				// 中止请求
@@ -34,3 +49,30 @@ func GlobalErrorHandler() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// bindError 转换请求参数绑定失败的错误，字段校验失败时返回包含各字段信息的参数校验异常，
+// 其他错误（如请求体格式错误）返回请求参数错误
+func bindError(err error) error {
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return buserr.ErrIllegalParameter
+	}
+
+	validErr := &buserr.ValidationError{}
+	for _, fieldErr := range fieldErrs {
+		// 请求参数名与字段名只有首字母大小写不同
+		field := []rune(fieldErr.Field())
+		field[0] = unicode.ToLower(field[0])
+		if fieldErr.Tag() == "required" {
+			validErr.AddMessage(string(field), i18n.ParamRequired, string(field))
+			continue
+		}
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule += "=" + fieldErr.Param()
+		}
+		validErr.AddMessage(string(field), i18n.ParamInvalid, string(field), rule)
+	}
+	return validErr
+}
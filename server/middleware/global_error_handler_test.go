@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGlobalErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		err      error
+		lang     string
+		wantHttp int
+		wantCode int
+		wantMsg  string
+	}{
+		{"business error", buserr.ErrRoleNotFound, "en", http.StatusOK, buserr.RoleNotFoundCode, "Role not found"},
+		{"database error", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'ops' for key 'sys_role.code'"}, "zh-CN",
+			http.StatusOK, buserr.DuplicateKeyCode, "数据已存在，请勿重复添加！"},
+		// 未知错误不向客户端返回原始的错误信息
+		{"unknown error", errors.New("dial tcp 10.0.0.1:3306: connect: connection refused"), "zh-CN",
+			http.StatusInternalServerError, http.StatusInternalServerError, "服务器内部错误，请稍后重试！"},
+		{"unknown error in english", errors.New("open /etc/gdmin/secret.key: permission denied"), "en",
+			http.StatusInternalServerError, http.StatusInternalServerError, "Internal server error, please try again later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(LocaleHandler(), GlobalErrorHandler())
+			router.GET("/test", func(c *gin.Context) { _ = c.Error(tt.err) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Accept-Language", tt.lang)
+			router.ServeHTTP(w, req)
+
+			var res struct {
+				Code int    `json:"code"`
+				Msg  string `json:"msg"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("response %s: %v", w.Body.String(), err)
+			}
+			if w.Code != tt.wantHttp || res.Code != tt.wantCode || res.Msg != tt.wantMsg {
+				t.Fatalf("response = %d %s, want %d code %d msg %q", w.Code, w.Body.String(), tt.wantHttp, tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
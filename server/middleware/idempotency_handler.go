@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/global"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader 幂等键请求头
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	idempotencyKeyPrefix = "sys:idempotency:"
+
+	// 幂等键的最大长度
+	idempotencyKeyMaxLen = 128
+
+	// 幂等键未配置有效期时的默认有效期
+	defaultIdempotencyTTL = 5 * time.Minute
+)
+
+var (
+	errIdempotencyKeyTooLong = buserr.NewNoticeBusErr("幂等键长度不能超过128！")
+	errIdempotencyProcessing = buserr.NewNoticeBusErr("相同的请求正在处理中，请稍后重试！")
+)
+
+// idempotencyResponse 缓存的首次响应
+type idempotencyResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyHandler 幂等处理，请求头携带 Idempotency-Key 时，有效期内相同幂等键的请求直接返回首次的响应而不再执行。
+// 幂等键按用户隔离，需放在jwt鉴权之后；使用请求级事务时需放在 TransactionHandler 之前，只缓存事务提交后的响应。
+// 处理失败（发生错误或响应状态码非2xx）时不缓存响应，允许使用相同的幂等键重试
+func IdempotencyHandler() gin.HandlerFunc {
+
+	store := cache.NewRedisCache()
+	return func(c *gin.Context) {
+
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > idempotencyKeyMaxLen {
+			_ = c.Error(errIdempotencyKeyTooLong)
+			c.Abort()
+			return
+		}
+
+		scope := "ip:" + c.ClientIP()
+		if claims, exists := c.Get(common.ClaimsKey); exists {
+			scope = "user:" + strconv.FormatUint(claims.(*common.UserClaims).ID, 10)
+		}
+		key := idempotencyKeyPrefix + scope + ":" + c.Request.Method + ":" + c.FullPath() + ":" + idempotencyKey
+
+		ctx := c.Request.Context()
+		ttl := time.Duration(global.Config.Cache.IdempotencyTTL) * time.Second
+		if ttl <= 0 {
+			ttl = defaultIdempotencyTTL
+		}
+
+		// 占用幂等键，值为空表示请求正在处理中
+		ok, err := store.SetNX(ctx, key, "", ttl)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		if !ok {
+			replayIdempotencyResponse(c, store, key)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		succeeded := false
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			if !succeeded {
+				if err := store.Del(ctx, key); err != nil {
+					zap.L().Error("释放幂等键失败：", zap.String("key", key), zap.Error(err))
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if len(c.Errors) > 0 || status < 200 || status >= 300 {
+			return
+		}
+
+		data, err := json.Marshal(&idempotencyResponse{
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if err != nil {
+			zap.L().Error("缓存幂等响应失败：", zap.String("key", key), zap.Error(err))
+			return
+		}
+		if err = store.Set(ctx, key, string(data), ttl); err != nil {
+			zap.L().Error("缓存幂等响应失败：", zap.String("key", key), zap.Error(err))
+			return
+		}
+		succeeded = true
+	}
+}
+
+// replayIdempotencyResponse 返回幂等键对应的首次响应，首次请求尚未完成时返回错误
+func replayIdempotencyResponse(c *gin.Context, store cache.Cache, key string) {
+
+	value, ok, err := store.Get(c.Request.Context(), key)
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	if !ok || value == "" {
+		_ = c.Error(errIdempotencyProcessing)
+		c.Abort()
+		return
+	}
+
+	var resp idempotencyResponse
+	if err = json.Unmarshal([]byte(value), &resp); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(resp.Status, resp.ContentType, resp.Body)
+	c.Abort()
+}
+
+// idempotencyResponseWriter 写出响应的同时记录响应内容
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
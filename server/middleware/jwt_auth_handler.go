@@ -10,7 +10,21 @@ import (
 )
 
 // JwtAuthHandler jwt token authentication middleware
+//
+// 除校验签名外，还会校验 token 中的令牌版本号与用户当前的令牌版本号是否一致，
+// 用户角色变更后旧 token 立即失效。令牌版本号优先从 redis 读取，缓存失效时才查询数据库，
+// 相比纯无状态的 jwt 每个请求多一次缓存查询，换取角色变更能即时生效
 func JwtAuthHandler() gin.HandlerFunc {
+	return jwtAuthHandler(true)
+}
+
+// JwtRefreshAuthHandler 刷新 token 使用的鉴权中间件，不校验令牌版本号，
+// 角色变更后持有旧 token 的用户可以直接换取携带最新角色的 token
+func JwtRefreshAuthHandler() gin.HandlerFunc {
+	return jwtAuthHandler(false)
+}
+
+func jwtAuthHandler(checkTokenVersion bool) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 
@@ -39,6 +53,20 @@ func JwtAuthHandler() gin.HandlerFunc {
 				return
 			}
 
+			if checkTokenVersion {
+				tokenVersion, err2 := cache.SysUserCache.GetSysUserTokenVersion(userClaims.ID)
+				if err2 != nil {
+					_ = c.Error(err2)
+					c.Abort()
+					return
+				}
+				if tokenVersion != userClaims.TokenVersion {
+					_ = c.Error(buserr.NewTokenAuthErr("用户角色已变更，请重新登录！"))
+					c.Abort()
+					return
+				}
+			}
+
 			c.Set(common.ClaimsKey, userClaims)
 			c.Next()
 		}
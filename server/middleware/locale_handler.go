@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleHandler 根据 Accept-Language 请求头选择响应信息的语言，并放入请求的上下文
+func LocaleHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		locale := i18n.MatchLocale(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+
+		c.Next()
+	}
+}
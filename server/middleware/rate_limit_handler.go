@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/limiter"
+	"gitee.com/nichanghao/gdmin/web/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+var (
+	rateLimitStore     limiter.Store
+	rateLimitStoreOnce sync.Once
+)
+
+// RateLimitHandler 令牌桶限流，group 为配置文件中的路由组名称，未配置时不限流。
+// 已登录的请求按用户id限流，需放在jwt鉴权之后；未登录的请求按客户端ip限流
+func RateLimitHandler(group string) gin.HandlerFunc {
+
+	limit, ok := global.Config.RateLimit.Groups[group]
+	if !ok || limit.Rate <= 0 || limit.Burst <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	store := getRateLimitStore()
+	return func(c *gin.Context) {
+
+		key := "rate-limit:" + group + ":ip:" + c.ClientIP()
+		if claims, exists := c.Get(common.ClaimsKey); exists {
+			key = "rate-limit:" + group + ":user:" + strconv.FormatUint(claims.(*common.UserClaims).ID, 10)
+		}
+
+		allowed, wait, err := store.Allow(c.Request.Context(), key, limit.Rate, limit.Burst)
+		// 限流存储异常时不拦截请求
+		if err != nil {
+			zap.L().Error("限流失败：", zap.String("key", key), zap.Error(err))
+			c.Next()
+			return
+		}
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			response.Result(http.StatusTooManyRequests, http.StatusTooManyRequests, nil, "请求过于频繁，请稍后再试！", c)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func getRateLimitStore() limiter.Store {
+	rateLimitStoreOnce.Do(func() {
+		switch global.Config.RateLimit.Store {
+		case "redis":
+			rateLimitStore = limiter.NewRedisStore()
+		default:
+			rateLimitStore = limiter.NewMemoryStore()
+		}
+	})
+	return rateLimitStore
+}
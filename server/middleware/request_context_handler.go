@@ -4,6 +4,7 @@ import (
 	"context"
 	"gitee.com/nichanghao/gdmin/common"
 	"github.com/gin-gonic/gin"
+	"reflect"
 )
 
 func RequestContextHandler(params ...interface{}) gin.HandlerFunc {
@@ -12,8 +13,9 @@ func RequestContextHandler(params ...interface{}) gin.HandlerFunc {
 		var data interface{}
 		var bindingMode = common.BindModeBody
 
-		if len(params) >= 1 {
-			data = params[0]
+		if len(params) >= 1 && params[0] != nil {
+			// 每个请求使用新的参数对象，避免并发请求之间共享数据
+			data = reflect.New(reflect.TypeOf(params[0]).Elem()).Interface()
 		}
 		if len(params) == 2 {
 			bindingMode = params[1].(common.BindMode)
@@ -23,16 +25,24 @@ func RequestContextHandler(params ...interface{}) gin.HandlerFunc {
 		switch bindingMode {
 		case common.BindModeQuery:
 			err = c.ShouldBindQuery(data)
+		case common.BindModeUri:
+			err = c.ShouldBindUri(data)
 		case common.BindModeBody:
 			if data != nil {
 				err = c.ShouldBindJSON(data)
 			}
 		default:
 		}
-
-		claims, err := common.USER_CTX.GetUserClaims(c)
+		// 参数绑定或校验失败时不再执行后续的处理函数
 		if err != nil {
-			_ = c.Error(err)
+			_ = c.Error(err).SetType(gin.ErrorTypeBind)
+			c.Abort()
+			return
+		}
+
+		claims, claimsErr := common.USER_CTX.GetUserClaims(c)
+		if claimsErr != nil {
+			_ = c.Error(claimsErr)
 			c.Abort()
 			return
 		}
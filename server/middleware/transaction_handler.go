@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"bytes"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/global"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// TransactionHandler 请求级事务，需放在 RequestContextHandler 之前。
+// 请求处理成功时提交事务，发生错误、响应状态码非2xx或 panic 时回滚事务。
+// 事务提交前响应会先缓存，提交失败时返回错误而不是已缓存的成功响应。
+// 仅用于需要保证原子性的写接口，只读接口不应使用
+func TransactionHandler() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		tx := global.GormDB.WithContext(c.Request.Context()).Begin()
+		if tx.Error != nil {
+			_ = c.Error(tx.Error)
+			c.Abort()
+			return
+		}
+
+		txCtx := &common.TxContext{DB: tx}
+		c.Request = c.Request.WithContext(common.WithTx(c.Request.Context(), txCtx))
+
+		writer := c.Writer
+		bufWriter := &txResponseWriter{ResponseWriter: writer, status: http.StatusOK}
+		c.Writer = bufWriter
+
+		committed := false
+		defer func() {
+			c.Writer = writer
+			if committed {
+				return
+			}
+			tx.Rollback()
+			if r := recover(); r != nil {
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		// 发生错误时丢弃缓存的响应，由全局错误处理返回错误信息
+		if len(c.Errors) > 0 {
+			return
+		}
+		if bufWriter.status < 200 || bufWriter.status >= 300 {
+			bufWriter.flush()
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			_ = c.Error(err)
+			return
+		}
+		committed = true
+		bufWriter.flush()
+		txCtx.RunAfterCommit()
+	}
+}
+
+// txResponseWriter 缓存响应，事务提交后再写出
+type txResponseWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *txResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *txResponseWriter) WriteHeaderNow() {}
+
+func (w *txResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *txResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *txResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *txResponseWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *txResponseWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// flush 将缓存的响应写出
+func (w *txResponseWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 与 initialize.InitMigrate 中迁移的模型保持一致
+var migrateModels = []any{
+	&SysUser{},
+	&SysRole{},
+	&SysMenu{},
+	&SysDept{},
+	&SysUserRole{},
+	&SysRoleMenu{},
+	&SysRoleDept{},
+	&SysOperationLog{},
+	&SysRoleHistory{},
+}
+
+func TestMigrateSqlite(t *testing.T) {
+	testMigrate(t, sqlite.Open(filepath.Join(t.TempDir(), "gdmin.db")))
+}
+
+// 设置 GDMIN_TEST_MYSQL_DSN 后在真实的 MySQL 上验证迁移
+func TestMigrateMysql(t *testing.T) {
+	dsn := os.Getenv("GDMIN_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("GDMIN_TEST_MYSQL_DSN not set")
+	}
+	testMigrate(t, mysql.New(mysql.Config{DSN: dsn, DefaultStringSize: 256, DontSupportRenameColumn: true}))
+}
+
+// 设置 GDMIN_TEST_POSTGRES_DSN 后在真实的 PostgreSQL 上验证迁移
+func TestMigratePostgres(t *testing.T) {
+	dsn := os.Getenv("GDMIN_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("GDMIN_TEST_POSTGRES_DSN not set")
+	}
+	testMigrate(t, postgres.Open(dsn))
+}
+
+// testMigrate 使用独立的表前缀迁移两次全部模型，确认表结构可以重复迁移，测试结束后删除创建的表
+func testMigrate(t *testing.T, dialector gorm.Dialector) {
+	t.Helper()
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         logger.Discard,
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "migrate_test_", SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Migrator().DropTable(migrateModels...)
+		if s, err := db.DB(); err == nil {
+			_ = s.Close()
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		if err = db.AutoMigrate(migrateModels...); err != nil {
+			t.Fatalf("AutoMigrate #%d: %v", i+1, err)
+		}
+	}
+
+	migrator := db.Migrator()
+	for _, column := range []string{"id", "name", "code", "desc", "status", "parent_id", "data_scope", "version", "deleted_at"} {
+		if !migrator.HasColumn(&SysRole{}, column) {
+			t.Errorf("table %s has no column %s", "migrate_test_sys_role", column)
+		}
+	}
+	role := SysRole{Name: "运维", Code: "ops", Desc: "运维人员"}
+	if err = db.Create(&role).Error; err != nil {
+		t.Fatalf("Create role: %v", err)
+	}
+	var got SysRole
+	if err = db.First(&got, role.Id).Error; err != nil || got.Name != role.Name || got.Desc != role.Desc {
+		t.Fatalf("First role = %+v, err %v", got, err)
+	}
+}
+
+// 多个租户使用不同的表前缀共用一个数据库，many2many 关联表同样带有表前缀
+func TestMigrateTablePrefix(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "gdmin.db")
+	openTenant := func(prefix string) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+			Logger:         logger.Discard,
+			NamingStrategy: schema.NamingStrategy{TablePrefix: prefix, SingularTable: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = db.AutoMigrate(migrateModels...); err != nil {
+			t.Fatalf("AutoMigrate %s: %v", prefix, err)
+		}
+		return db
+	}
+	t1, t2 := openTenant("t1_"), openTenant("t2_")
+
+	migrator := t1.Migrator()
+	for _, table := range []string{"t1_sys_role", "t1_sys_user_role", "t1_sys_role_menu", "t1_sys_role_dept", "t2_sys_user_role"} {
+		if !migrator.HasTable(table) {
+			t.Errorf("table %s not created", table)
+		}
+	}
+	if migrator.HasTable("sys_user_role") {
+		t.Error("join table created without the table prefix")
+	}
+	// 索引名在整个数据库中唯一，同样需要带有表前缀
+	if !migrator.HasIndex(&SysRoleHistory{}, "idx_t1_sys_role_history_revision") {
+		t.Error("index idx_t1_sys_role_history_revision not created")
+	}
+
+	role := SysRole{Name: "运维", Code: "ops"}
+	if err := t1.Create(&role).Error; err != nil {
+		t.Fatal(err)
+	}
+	user := SysUser{Username: "u1", Roles: []SysRole{role}}
+	if err := t1.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var joins int64
+	t1.Table("t1_sys_user_role").Where("sys_role_id = ? AND sys_user_id = ?", role.Id, user.Id).Count(&joins)
+	if joins != 1 {
+		t.Fatalf("t1_sys_user_role rows = %d, want 1", joins)
+	}
+	var users []SysUser
+	if err := t1.Model(&role).Association("Users").Find(&users); err != nil || len(users) != 1 || users[0].Id != user.Id {
+		t.Fatalf("role users = %+v, err %v", users, err)
+	}
+	if count := t2.Model(&SysRole{Id: role.Id}).Association("Users").Count(); count != 0 {
+		t.Fatalf("tenant t2 role users = %d, want 0", count)
+	}
+}
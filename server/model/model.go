@@ -8,4 +8,33 @@ type (
 	SysUser = system.SysUser
 	SysRole = system.SysRole
 	SysMenu = system.SysMenu
+	SysDept = system.SysDept
+
+	SysOperationLog = system.SysOperationLog
+
+	SysRoleHistory  = system.SysRoleHistory
+	SysRoleSnapshot = system.SysRoleSnapshot
+
+	SysUserRole = system.SysUserRole
+	SysRoleDept = system.SysRoleDept
+	SysRoleMenu = system.SysRoleMenu
+)
+
+// 操作类型
+const (
+	OperationCreate = system.OperationCreate
+	OperationUpdate = system.OperationUpdate
+	OperationDelete = system.OperationDelete
+)
+
+// SuperAdminRoleCode 内置超级管理员角色编码
+const SuperAdminRoleCode = system.SuperAdminRoleCode
+
+// 角色数据权限范围
+const (
+	DataScopeAll        = system.DataScopeAll
+	DataScopeDeptAndSub = system.DataScopeDeptAndSub
+	DataScopeDept       = system.DataScopeDept
+	DataScopeSelf       = system.DataScopeSelf
+	DataScopeCustom     = system.DataScopeCustom
 )
@@ -0,0 +1,25 @@
+package system
+
+import (
+	"gitee.com/nichanghao/gdmin/common"
+	"gorm.io/gorm/schema"
+)
+
+type SysDept struct {
+	Id       uint64 `gorm:"primarykey;comment:部门ID" json:"id"`
+	Name     string `gorm:"size:32;comment:部门名称" json:"name"`
+	ParentId uint64 `gorm:"default:0;comment:父部门ID" json:"parentId"`
+	Status   uint8  `gorm:"default:1;comment:状态(1:启用 2:禁用)" json:"status"`
+	common.BaseDO
+}
+
+// SysRoleDept 角色与部门的关联关系（自定义数据权限）
+type SysRoleDept struct {
+	SysRoleId uint64 `gorm:"primarykey;comment:角色ID"`
+	SysDeptId uint64 `gorm:"primarykey;comment:部门ID"`
+}
+
+// TableName 与 SysRole.Depts 的 many2many 关联表一致，表前缀由命名策略统一添加
+func (SysRoleDept) TableName(namer schema.Namer) string {
+	return namer.JoinTableName("sys_role_dept")
+}
@@ -0,0 +1,24 @@
+package system
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 操作类型
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// SysOperationLog 操作日志，记录数据变更前后的差异
+type SysOperationLog struct {
+	Id         uint64          `gorm:"primarykey;comment:日志ID" json:"id"`
+	UserId     uint64          `gorm:"comment:操作人ID" json:"userId"`
+	Action     string          `gorm:"size:16;comment:操作类型(create,update,delete)" json:"action"`
+	Resource   string          `gorm:"size:32;index:,composite:resource;comment:资源类型" json:"resource"`
+	ResourceId uint64          `gorm:"index:,composite:resource;comment:资源ID" json:"resourceId"`
+	Diff       json.RawMessage `gorm:"type:json;comment:变更的字段" json:"diff"`
+	CreatedAt  time.Time       `gorm:"comment:操作时间" json:"createdAt"`
+}
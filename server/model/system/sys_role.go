@@ -2,14 +2,71 @@ package system
 
 import (
 	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"regexp"
+	"unicode/utf8"
 )
 
+// 角色数据权限范围
+const (
+	DataScopeAll        int8 = iota + 1 // 全部数据
+	DataScopeDeptAndSub                 // 本部门及以下数据
+	DataScopeDept                       // 本部门数据
+	DataScopeSelf                       // 仅本人数据
+	DataScopeCustom                     // 自定义部门数据
+)
+
+// 角色字段长度限制，与数据库字段长度一致
+const (
+	RoleNameMaxLen = 32
+	RoleCodeMaxLen = 32
+	RoleDescMaxLen = 255
+)
+
+// 角色编码允许的字符
+var roleCodePattern = regexp.MustCompile(`^[a-zA-Z0-9_:-]+$`)
+
+// SuperAdminRoleCode 内置超级管理员角色编码，拥有该编码的角色不能被删除
+const SuperAdminRoleCode = "super_admin"
+
+// SysRole 角色，编码不区分大小写且统一以小写存储。
+// 编码的唯一性由服务层校验，如需数据库兜底可为 code 建立唯一索引（已逻辑删除的角色仍会占用编码）
 type SysRole struct {
-	Id     uint64    `gorm:"primarykey;comment:角色ID" json:"id"`
-	Name   string    `gorm:"type:varchar(32);comment:角色名" json:"name"`
-	Code   string    `gorm:"type:varchar(32);comment:角色标识" json:"code"`
-	Status uint8     `gorm:"type:tinyint(1);default:1;comment:状态(1:启用 2:禁用)" json:"status"`
-	Desc   string    `gorm:"type:varchar(255);comment:备注" json:"desc"`
-	Users  []SysUser `gorm:"many2many:sys_user_role;" json:"users"` // 角色与用户的多对多关系
+	Id        uint64    `gorm:"primarykey;comment:角色ID" json:"id"`
+	Name      string    `gorm:"size:32;comment:角色名" json:"name"`
+	Code      string    `gorm:"size:32;comment:角色标识" json:"code"`
+	Status    uint8     `gorm:"default:1;comment:状态(1:启用 2:禁用)" json:"status"`
+	Desc      string    `gorm:"size:255;comment:备注" json:"desc"`
+	DataScope int8      `gorm:"default:1;comment:数据权限范围(1:全部,2:本部门及以下,3:本部门,4:仅本人,5:自定义)" json:"dataScope"`
+	ParentId  uint64    `gorm:"default:0;comment:父角色ID(0:无父角色)" json:"parentId"` // 角色继承父角色的菜单权限
+	Users     []SysUser `gorm:"many2many:sys_user_role;" json:"users,omitempty"` // 角色与用户的多对多关系，用户量大时不预加载，通过 GetRoleUsers 分页查询
+	Depts     []SysDept `gorm:"many2many:sys_role_dept;" json:"depts,omitempty"` // 自定义数据权限时角色可查看的部门
 	common.BaseDO
 }
+
+// Validate 校验角色字段是否符合数据库字段的限制，长度按字符数计算
+func (role *SysRole) Validate() error {
+
+	validErr := &buserr.ValidationError{}
+	if utf8.RuneCountInString(role.Name) > RoleNameMaxLen {
+		validErr.AddMessage("name", i18n.RoleNameTooLong, RoleNameMaxLen)
+	}
+	switch {
+	case role.Code == "":
+		validErr.AddMessage("code", i18n.RoleCodeRequired)
+	case utf8.RuneCountInString(role.Code) > RoleCodeMaxLen:
+		validErr.AddMessage("code", i18n.RoleCodeTooLong, RoleCodeMaxLen)
+	case !roleCodePattern.MatchString(role.Code):
+		validErr.AddMessage("code", i18n.RoleCodeInvalidChar)
+	}
+	if utf8.RuneCountInString(role.Desc) > RoleDescMaxLen {
+		validErr.AddMessage("desc", i18n.RoleDescTooLong, RoleDescMaxLen)
+	}
+	// 0 表示未设置，新增时使用默认值，修改时不更新
+	if role.DataScope != 0 && (role.DataScope < DataScopeAll || role.DataScope > DataScopeCustom) {
+		validErr.AddMessage("dataScope", i18n.RoleDataScopeInvalid, DataScopeAll, DataScopeCustom)
+	}
+
+	return validErr.ErrOrNil()
+}
@@ -0,0 +1,34 @@
+package system
+
+import "time"
+
+// SysRoleHistory 角色历史版本，角色每次新增、修改、删除时在同一事务中保存角色的完整数据，
+// 同一角色的修订号从1开始递增
+type SysRoleHistory struct {
+	Id        uint64           `gorm:"primarykey;comment:历史ID" json:"id"`
+	RoleId    uint64           `gorm:"uniqueIndex:,composite:revision;comment:角色ID" json:"roleId"`
+	Revision  uint64           `gorm:"uniqueIndex:,composite:revision;comment:修订号" json:"revision"`
+	Action    string           `gorm:"size:16;comment:操作类型(create,update,delete)" json:"action"`
+	Snapshot  *SysRoleSnapshot `gorm:"type:json;serializer:json;comment:角色数据快照" json:"snapshot,omitempty"`
+	UserId    uint64           `gorm:"comment:操作人ID" json:"userId"`
+	CreatedAt time.Time        `gorm:"comment:修订时间" json:"createdAt"`
+}
+
+// SysRoleSnapshot 角色在某个修订时的完整数据，包含绑定的菜单和自定义数据权限的部门
+type SysRoleSnapshot struct {
+	Id        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Code      string     `json:"code"`
+	Status    uint8      `json:"status"`
+	Desc      string     `json:"desc"`
+	DataScope int8       `json:"dataScope"`
+	ParentId  uint64     `json:"parentId"`
+	MenuIds   []uint64   `json:"menuIds"`
+	DeptIds   []uint64   `json:"deptIds"`
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt"` // 角色已删除时为删除时间
+	CreatedBy uint64     `json:"createdBy"`
+	UpdatedBy uint64     `json:"updatedBy"`
+}
@@ -0,0 +1,60 @@
+package system
+
+import (
+	"errors"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSysRoleValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       SysRole
+		wantFields []string
+	}{
+		{"valid", SysRole{Name: "运维", Code: "sys:ops_1-a"}, nil},
+		{"name at max", SysRole{Name: strings.Repeat("名", RoleNameMaxLen), Code: "ops"}, nil},
+		{"name over max", SysRole{Name: strings.Repeat("名", RoleNameMaxLen+1), Code: "ops"}, []string{"name"}},
+		{"code empty", SysRole{Name: "运维"}, []string{"code"}},
+		{"code at max", SysRole{Name: "运维", Code: strings.Repeat("a", RoleCodeMaxLen)}, nil},
+		{"code over max", SysRole{Name: "运维", Code: strings.Repeat("a", RoleCodeMaxLen+1)}, []string{"code"}},
+		{"code with space", SysRole{Name: "运维", Code: "o ps"}, []string{"code"}},
+		{"code with non ascii", SysRole{Name: "运维", Code: "运维"}, []string{"code"}},
+		{"desc at max", SysRole{Name: "运维", Code: "ops", Desc: strings.Repeat("备", RoleDescMaxLen)}, nil},
+		{"desc over max", SysRole{Name: "运维", Code: "ops", Desc: strings.Repeat("备", RoleDescMaxLen+1)}, []string{"desc"}},
+		{"data scope unset", SysRole{Name: "运维", Code: "ops", DataScope: 0}, nil},
+		{"data scope custom", SysRole{Name: "运维", Code: "ops", DataScope: DataScopeCustom}, nil},
+		{"data scope over range", SysRole{Name: "运维", Code: "ops", DataScope: DataScopeCustom + 1}, []string{"dataScope"}},
+		{"data scope negative", SysRole{Name: "运维", Code: "ops", DataScope: -1}, []string{"dataScope"}},
+		{"multiple fields", SysRole{Name: strings.Repeat("名", RoleNameMaxLen+1), Desc: strings.Repeat("备", RoleDescMaxLen+1)}, []string{"name", "code", "desc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.role.Validate()
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var validErr *buserr.ValidationError
+			if !errors.As(err, &validErr) {
+				t.Fatalf("Validate() = %v, want *buserr.ValidationError", err)
+			}
+			fields := make([]string, 0, len(validErr.Fields))
+			for _, field := range validErr.Fields {
+				if field.Message == "" {
+					t.Errorf("field %s has no message", field.Field)
+				}
+				fields = append(fields, field.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Fatalf("invalid fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
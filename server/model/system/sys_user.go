@@ -2,17 +2,31 @@ package system
 
 import (
 	"gitee.com/nichanghao/gdmin/common"
+	"gorm.io/gorm/schema"
 )
 
 type SysUser struct {
-	Id       uint64    `gorm:"primarykey;comment:用户ID" json:"id"`
-	Username string    `gorm:"index;type:varchar(128);comment:用户登录名" json:"username"`
-	Password string    `gorm:"type:varchar(64);comment:用户登录密码" json:"-"`
-	Nickname string    `gorm:"type:varchar(128);comment:用户昵称" json:"nickname"`
-	Gender   uint8     `gorm:"type:tinyint(1);comment:性别(1:男,2:女)" json:"gender"`
-	Phone    string    `gorm:"type:varchar(16);comment:联系电话" json:"phone"`
-	Email    string    `gorm:"type:varchar(64);comment:邮箱" json:"email"`
-	Status   uint8     `gorm:"type:tinyint(1);default:1;comment:用户状态(1:正常,2:停用)" json:"status"`
-	Roles    []SysRole `gorm:"many2many:sys_user_role;" json:"roles"` // 用户角色关系
+	Id           uint64    `gorm:"primarykey;comment:用户ID" json:"id"`
+	Username     string    `gorm:"index;size:128;comment:用户登录名" json:"username"`
+	Password     string    `gorm:"size:64;comment:用户登录密码" json:"-"`
+	Nickname     string    `gorm:"size:128;comment:用户昵称" json:"nickname"`
+	Gender       uint8     `gorm:"comment:性别(1:男,2:女)" json:"gender"`
+	Phone        string    `gorm:"size:16;comment:联系电话" json:"phone"`
+	Email        string    `gorm:"size:64;comment:邮箱" json:"email"`
+	Status       uint8     `gorm:"default:1;comment:用户状态(1:正常,2:停用)" json:"status"`
+	DeptId       uint64    `gorm:"default:0;comment:部门ID" json:"deptId"`
+	TokenVersion int       `gorm:"default:0;comment:令牌版本号" json:"-"`      // 用户角色变更时递增，使已签发的令牌失效
+	Roles        []SysRole `gorm:"many2many:sys_user_role;" json:"roles"` // 用户角色关系
 	common.BaseDO
 }
+
+// SysUserRole 用户与角色的关联关系
+type SysUserRole struct {
+	SysRoleId uint64 `gorm:"primarykey;comment:角色ID"`
+	SysUserId uint64 `gorm:"primarykey;comment:用户ID"`
+}
+
+// TableName 与 SysUser.Roles 的 many2many 关联表一致，表前缀由命名策略统一添加
+func (SysUserRole) TableName(namer schema.Namer) string {
+	return namer.JoinTableName("sys_user_role")
+}
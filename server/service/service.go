@@ -7,7 +7,11 @@ var (
 
 	SysMenu = &system.SysMenuService{}
 
-	SysRole = &system.SysRoleService{}
+	SysRole = system.MetricsRole
 
 	SysCasbin = &system.SysCasbinService{}
+
+	SysAudit = &system.SysAuditService{}
+
+	SysHealth = &system.SysHealthService{}
 )
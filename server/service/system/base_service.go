@@ -0,0 +1,102 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/global"
+	"gorm.io/gorm"
+)
+
+// BaseService 通用的增删改查服务，T 为嵌入 common.BaseDO 且主键为 uint64 类型 Id 的模型。
+// 删除为逻辑删除，查询会自动过滤已删除的数据
+type BaseService[T any] struct {
+	db *gorm.DB
+}
+
+// QueryOption 查询选项，用于调整查询语句
+type QueryOption func(db *gorm.DB) *gorm.DB
+
+// SelectColumns 只查询指定的字段，未指定的字段及关联数据不会被加载
+func SelectColumns(cols ...string) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(cols)
+	}
+}
+
+// applyQueryOptions 将查询选项应用到查询语句
+func applyQueryOptions(db *gorm.DB, opts []QueryOption) *gorm.DB {
+	for _, opt := range opts {
+		db = opt(db)
+	}
+	return db
+}
+
+// NewBaseService 创建通用服务，db 为空时使用全局数据库连接
+func NewBaseService[T any](db *gorm.DB) BaseService[T] {
+	return BaseService[T]{db: db}
+}
+
+// DB 获取服务使用的数据库连接
+func (s *BaseService[T]) DB() *gorm.DB {
+	if s.db != nil {
+		return s.db
+	}
+	return global.GormDB
+}
+
+// Create 新增数据，新增成功后主键会回填至 entity
+func (s *BaseService[T]) Create(ctx context.Context, entity *T) error {
+
+	return s.DB().WithContext(ctx).Create(entity).Error
+}
+
+// GetById 根据主键查询数据，数据不存在时返回 gorm.ErrRecordNotFound
+func (s *BaseService[T]) GetById(ctx context.Context, id uint64, opts ...QueryOption) (*T, error) {
+
+	var entity T
+	if err := applyQueryOptions(s.DB().WithContext(ctx), opts).Where("id = ?", id).First(&entity).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// Update 根据 entity 的主键更新非零值字段，数据不存在时返回 gorm.ErrRecordNotFound
+func (s *BaseService[T]) Update(ctx context.Context, entity *T) error {
+
+	result := s.DB().WithContext(ctx).Model(entity).Updates(entity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteById 根据主键逻辑删除数据
+func (s *BaseService[T]) DeleteById(ctx context.Context, id uint64) error {
+
+	return s.DB().WithContext(ctx).Where("id = ?", id).Delete(new(T)).Error
+}
+
+// List 分页查询数据，按主键倒序排列，page 从 1 开始，查询选项只作用于列表查询
+func (s *BaseService[T]) List(ctx context.Context, page, size int, opts ...QueryOption) ([]T, int64, error) {
+
+	tx := s.DB().WithContext(ctx).Model(new(T))
+
+	// 查询数量
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return make([]T, 0), 0, nil
+	}
+
+	// 查询列表
+	list := make([]T, 0, size)
+	if err := applyQueryOptions(tx, opts).Order("id DESC").Limit(size).Offset((page - 1) * size).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
@@ -0,0 +1,69 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/model"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestBaseServiceRoleCRUD(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	roleService := &SysRoleService{}
+
+	// 新增后主键回填
+	role := &model.SysRole{Name: "运维", Code: "ops", Desc: "运维人员"}
+	if err := roleService.Create(ctx, role); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if role.Id == 0 {
+		t.Fatal("Create did not fill the uint64 primary key")
+	}
+	if err := roleService.Create(ctx, &model.SysRole{Name: "审计", Code: "audit"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := roleService.GetById(ctx, role.Id)
+	if err != nil {
+		t.Fatalf("GetById: %v", err)
+	}
+	if got.Name != "运维" || got.Code != "ops" || got.Desc != "运维人员" {
+		t.Fatalf("GetById = %+v", got)
+	}
+
+	// 只更新非零值字段
+	if err = roleService.Update(ctx, &model.SysRole{Id: role.Id, Name: "运维组"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ = roleService.GetById(ctx, role.Id); got.Name != "运维组" || got.Code != "ops" {
+		t.Fatalf("after Update = %+v", got)
+	}
+	if err = roleService.Update(ctx, &model.SysRole{Id: 999, Name: "x"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Update missing role err = %v, want ErrRecordNotFound", err)
+	}
+
+	list, total, err := roleService.List(ctx, 1, 1, SelectColumns("id", "name"))
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 2 || len(list) != 1 || list[0].Name != "审计" || list[0].Code != "" {
+		t.Fatalf("List = %+v, total %d", list, total)
+	}
+
+	// 逻辑删除后查询不到，数据仍保留
+	if err = roleService.DeleteById(ctx, role.Id); err != nil {
+		t.Fatalf("DeleteById: %v", err)
+	}
+	if _, err = roleService.GetById(ctx, role.Id); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetById deleted role err = %v, want ErrRecordNotFound", err)
+	}
+	if _, total, _ = roleService.List(ctx, 1, 10); total != 1 {
+		t.Fatalf("List total after delete = %d, want 1", total)
+	}
+	var deleted model.SysRole
+	if err = db.Unscoped().First(&deleted, role.Id).Error; err != nil || !deleted.DeletedAt.Valid {
+		t.Fatalf("deleted role = %+v, err %v, want soft-deleted row", deleted, err)
+	}
+}
@@ -0,0 +1,89 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/model"
+	"github.com/casbin/casbin/v2"
+	casbinModel "github.com/casbin/casbin/v2/model"
+	"github.com/glebarez/sqlite"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// setupTestDB 使用 sqlite 创建测试数据库并替换全局的数据库连接及 casbin 执行器，测试结束后恢复。
+// 测试中不连接 redis，删除令牌版本号缓存等操作会失败并只记录日志
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "gdmin.db")), &gorm.Config{
+		Logger:         logger.Discard,
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(
+		&model.SysUser{},
+		&model.SysRole{},
+		&model.SysMenu{},
+		&model.SysDept{},
+		&model.SysUserRole{},
+		&model.SysRoleMenu{},
+		&model.SysRoleDept{},
+		&model.SysOperationLog{},
+		&model.SysRoleHistory{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	rbacModel, err := casbinModel.NewModelFromFile("../../rbac_model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := casbin.NewCachedEnforcer(rbacModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldDB, oldEnforcer, oldRedis := global.GormDB, global.Enforcer, global.RedisCli
+	global.GormDB, global.Enforcer = db, enforcer
+	global.RedisCli = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	t.Cleanup(func() {
+		_ = global.RedisCli.Close()
+		global.GormDB, global.Enforcer, global.RedisCli = oldDB, oldEnforcer, oldRedis
+	})
+	return db
+}
+
+// captureEvents 替换全局的事件总线，返回的函数等待订阅者处理完成后返回已发布事件的主题
+func captureEvents(t *testing.T) func() []string {
+	t.Helper()
+
+	var mu sync.Mutex
+	var topics []string
+	oldBus := event.Bus
+	event.Bus = event.NewEventBus()
+	event.Bus.Subscribe(event.SubscriberFunc(func(_ context.Context, e event.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		topics = append(topics, e.Topic())
+		return nil
+	}))
+	t.Cleanup(func() { event.Bus = oldBus })
+
+	return func() []string {
+		if err := event.Bus.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return topics
+	}
+}
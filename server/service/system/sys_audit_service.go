@@ -0,0 +1,152 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"reflect"
+	"strconv"
+)
+
+// 审计的资源类型
+const (
+	AuditResourceRole = "role"
+)
+
+var (
+	AuditService = new(SysAuditService)
+)
+
+type SysAuditService struct{}
+
+// auditFieldDiff 字段变更前后的值
+type auditFieldDiff struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Record 记录操作日志，仅保存 before 与 after 之间发生变化的字段，操作人从上下文中获取。
+// 上下文中存在请求级事务时，日志与数据变更在同一事务中写入
+func (auditService *SysAuditService) Record(ctx context.Context, action, resource string, before, after interface{}) error {
+	return auditService.RecordTx(ctx, common.DBFromContext(ctx), action, resource, before, after)
+}
+
+// RecordTx 在数据变更的事务中记录操作日志，记录失败时返回错误，由调用方回滚事务
+func (*SysAuditService) RecordTx(ctx context.Context, tx *gorm.DB, action, resource string, before, after interface{}) error {
+
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return err
+	}
+
+	diff := make(map[string]auditFieldDiff)
+	for key, value := range beforeFields {
+		if afterValue, ok := afterFields[key]; !ok || !reflect.DeepEqual(value, afterValue) {
+			diff[key] = auditFieldDiff{Before: value, After: afterFields[key]}
+		}
+	}
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			diff[key] = auditFieldDiff{After: value}
+		}
+	}
+	// 没有字段变化时不记录
+	if len(diff) == 0 {
+		return nil
+	}
+
+	diffJson, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+
+	// 资源ID优先取变更后的数据，删除时取变更前的数据
+	resourceId := auditResourceId(afterFields)
+	if resourceId == 0 {
+		resourceId = auditResourceId(beforeFields)
+	}
+
+	log := &model.SysOperationLog{
+		UserId:     common.USER_CTX.GetUserId(&ctx),
+		Action:     action,
+		Resource:   resource,
+		ResourceId: resourceId,
+		Diff:       diffJson,
+	}
+	return tx.Create(log).Error
+}
+
+// RecordRole 记录角色的操作日志，记录失败不影响已完成的角色变更
+func (auditService *SysAuditService) RecordRole(ctx context.Context, action string, before, after *model.SysRole) {
+
+	var beforeObj, afterObj interface{}
+	if before != nil {
+		beforeObj = before
+	}
+	if after != nil {
+		afterObj = after
+	}
+	if err := auditService.Record(ctx, action, AuditResourceRole, beforeObj, afterObj); err != nil {
+		zap.L().Error("记录角色操作日志失败：", zap.String("action", action), zap.Error(err))
+	}
+}
+
+// RecordRoleTx 在角色变更的事务中记录角色的操作日志，记录失败时角色变更一起回滚
+func (auditService *SysAuditService) RecordRoleTx(ctx context.Context, tx *gorm.DB, action string, before, after *model.SysRole) error {
+
+	var beforeObj, afterObj interface{}
+	if before != nil {
+		beforeObj = before
+	}
+	if after != nil {
+		afterObj = after
+	}
+	return auditService.RecordTx(ctx, tx, action, AuditResourceRole, beforeObj, afterObj)
+}
+
+// ListRoleLogs 获取角色的操作历史，按时间正序排列
+func (*SysAuditService) ListRoleLogs(ctx context.Context, roleId uint64) (logs []*model.SysOperationLog, err error) {
+
+	logs = make([]*model.SysOperationLog, 0)
+	err = common.DBFromContext(ctx).Model(&model.SysOperationLog{}).
+		Where("resource = ? AND resource_id = ?", AuditResourceRole, roleId).
+		Order("created_at ASC, id ASC").Find(&logs).Error
+	return
+}
+
+// auditFields 将对象转换为字段集合，对象为空时返回空集合
+func auditFields(obj interface{}) (map[string]any, error) {
+
+	fields := make(map[string]any)
+	if obj == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	// 使用 json.Number 避免大整数丢失精度
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// auditResourceId 从字段集合中获取资源ID
+func auditResourceId(fields map[string]any) uint64 {
+
+	id, _ := fields["id"].(json.Number)
+	num, _ := strconv.ParseUint(id.String(), 10, 64)
+	return num
+}
@@ -0,0 +1,102 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"strings"
+	"testing"
+)
+
+// roleLogs 查询角色的操作日志，返回各条日志的操作类型及变更的字段
+func roleLogs(t *testing.T, roleId uint64) ([]string, []map[string]json.RawMessage) {
+	t.Helper()
+
+	logs, err := AuditService.ListRoleLogs(context.Background(), roleId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := make([]string, 0, len(logs))
+	diffs := make([]map[string]json.RawMessage, 0, len(logs))
+	for _, log := range logs {
+		var diff map[string]json.RawMessage
+		if err = json.Unmarshal(log.Diff, &diff); err != nil {
+			t.Fatal(err)
+		}
+		actions = append(actions, log.Action)
+		diffs = append(diffs, diff)
+	}
+	return actions, diffs
+}
+
+func TestRestoreRoleRecordsAudit(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	role := model.SysRole{Name: "运维", Code: "ops", Status: 1}
+	db.Create(&role)
+	if _, err := RoleService.DeleteRole(&common.Request{Data: &request.SysRoleDeleteReq{Id: role.Id}, Context: ctx}); err != nil {
+		t.Fatalf("DeleteRole: %v", err)
+	}
+	if err := RoleService.RestoreRole(&common.Request{Data: &request.QueryIdReq{Id: role.Id}, Context: ctx}); err != nil {
+		t.Fatalf("RestoreRole: %v", err)
+	}
+
+	actions, diffs := roleLogs(t, role.Id)
+	if len(actions) != 2 || actions[0] != model.OperationDelete || actions[1] != model.OperationUpdate {
+		t.Fatalf("audit actions = %v, want [delete update]", actions)
+	}
+	if deleted := string(diffs[1]["deleted"]); deleted != `{"before":true,"after":false}` {
+		t.Fatalf("restore diff deleted = %s, diff %v", deleted, diffs[1])
+	}
+}
+
+func TestImportRolesRecordsAudit(t *testing.T) {
+	db := setupTestDB(t)
+
+	csv := "name,code,desc\n运维,ops,运维人员\n审计,audit,\n"
+	imported, rowErrs, err := RoleService.ImportRoles(context.Background(), strings.NewReader(csv), true)
+	if err != nil || imported != 2 || len(rowErrs) != 0 {
+		t.Fatalf("ImportRoles = %d, %v, %v", imported, rowErrs, err)
+	}
+
+	var roles []model.SysRole
+	db.Order("id").Find(&roles)
+	for _, role := range roles {
+		actions, diffs := roleLogs(t, role.Id)
+		if len(actions) != 1 || actions[0] != model.OperationCreate {
+			t.Fatalf("role %s audit actions = %v, want [create]", role.Code, actions)
+		}
+		if code := string(diffs[0]["code"]); code != `{"before":null,"after":"`+role.Code+`"}` {
+			t.Fatalf("role %s create diff code = %s", role.Code, code)
+		}
+	}
+}
+
+func TestImportRolesJsonRecordsAudit(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	exist := model.SysRole{Name: "运维", Code: "ops", Status: 1}
+	db.Create(&exist)
+	data := `{"roles":[{"name":"运维组","code":"ops","status":1},{"name":"审计","code":"audit","status":1}]}`
+	resp, err := RoleService.ImportRolesJson(ctx, []byte(data), request.SysRoleImportJsonReq{OnConflict: RoleConflictOverwrite})
+	if err != nil || resp.Created != 1 || resp.Updated != 1 {
+		t.Fatalf("ImportRolesJson = %+v, %v", resp, err)
+	}
+
+	actions, diffs := roleLogs(t, exist.Id)
+	if len(actions) != 1 || actions[0] != model.OperationUpdate {
+		t.Fatalf("overwritten role audit actions = %v, want [update]", actions)
+	}
+	if name := string(diffs[0]["name"]); name != `{"before":"运维","after":"运维组"}` {
+		t.Fatalf("overwrite diff name = %s", name)
+	}
+	var created model.SysRole
+	db.Where("code = ?", "audit").First(&created)
+	if actions, _ = roleLogs(t, created.Id); len(actions) != 1 || actions[0] != model.OperationCreate {
+		t.Fatalf("created role audit actions = %v, want [create]", actions)
+	}
+}
@@ -1,8 +1,11 @@
 package system
 
 import (
+	"context"
+	"gitee.com/nichanghao/gdmin/common"
 	"gitee.com/nichanghao/gdmin/global"
 	"gitee.com/nichanghao/gdmin/model"
+	"go.uber.org/zap"
 	"strconv"
 )
 
@@ -10,7 +13,7 @@ var (
 	CasbinService = new(SysCasbinService)
 )
 
-// casbin 中 区分角色和用户的前缀
+// casbin 中 区分角色和用户的前缀，角色以角色ID作为主体，修改角色名称或编码时无需同步权限策略
 const (
 	rolePrefix = "r:"
 
@@ -19,6 +22,92 @@ const (
 
 type SysCasbinService struct{}
 
+// policyChanges 事务中待执行的casbin权限策略修改。casbin 的适配器使用独立的数据库连接写入策略，
+// 在事务中修改的策略不会随事务回滚，因此事务中只记录需要执行的修改，事务提交后再执行
+type policyChanges []func() error
+
+// add 记录待执行的策略修改
+func (c *policyChanges) add(fn func() error) {
+	*c = append(*c, fn)
+}
+
+// applyAfterCommit 事务提交后按顺序执行策略修改，存在请求级事务时在请求级事务提交后执行。
+// 执行时数据已提交，修改失败只记录日志
+func (c policyChanges) applyAfterCommit(ctx context.Context) {
+	if len(c) == 0 {
+		return
+	}
+	common.AfterCommit(ctx, func() {
+		for _, fn := range c {
+			if err := fn(); err != nil {
+				zap.L().Error("修改casbin权限策略失败：", zap.Error(err))
+			}
+		}
+	})
+}
+
+// LoadPolicy 从数据库重新加载权限策略
+func (*SysCasbinService) LoadPolicy() error {
+
+	if err := global.Enforcer.LoadPolicy(); err != nil {
+		return err
+	}
+
+	return global.Enforcer.InvalidateCache()
+}
+
+// Enforce 校验主体是否拥有权限标识，主体为 GetCasbinUserStr 或 GetCasbinRoleStr 返回的用户或角色。
+// 权限模型（rbac_model.conf）按权限标识而不是请求路径和方法校验，路由对应的权限标识由 addPermissionRouter 注册，
+// 路由路径调整时无需修改策略；角色以id而不是编码作为主体，修改角色编码时也无需同步策略
+func (*SysCasbinService) Enforce(sub, permission string) (bool, error) {
+
+	return global.Enforcer.Enforce(sub, permission)
+}
+
+// UpdateRolePolicies 覆盖角色的权限策略，policies 中每一项为 [权限标识, 菜单ID]
+func (casbinService *SysCasbinService) UpdateRolePolicies(roleId uint64, policies [][]string) error {
+
+	roleStr := casbinService.GetCasbinRoleStr(roleId)
+	if _, err := global.Enforcer.RemoveFilteredPolicy(0, roleStr); err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	rules := make([][]string, 0, len(policies))
+	for i := range policies {
+		rules = append(rules, append([]string{roleStr}, policies[i]...))
+	}
+	_, err := global.Enforcer.AddPolicies(rules)
+
+	return err
+}
+
+// DeleteRole 删除角色的所有权限策略及用户与角色的关联
+func (casbinService *SysCasbinService) DeleteRole(roleId uint64) error {
+
+	_, err := global.Enforcer.DeleteRole(casbinService.GetCasbinRoleStr(roleId))
+
+	return err
+}
+
+// SetRoleParent 设置角色继承的父角色，parentId 为 0 时仅移除原有的继承关系
+func (casbinService *SysCasbinService) SetRoleParent(roleId, parentId uint64) error {
+
+	roleStr := casbinService.GetCasbinRoleStr(roleId)
+	if _, err := global.Enforcer.RemoveFilteredGroupingPolicy(0, roleStr); err != nil {
+		return err
+	}
+	if parentId == 0 {
+		return nil
+	}
+
+	_, err := global.Enforcer.AddGroupingPolicy(roleStr, casbinService.GetCasbinRoleStr(parentId))
+
+	return err
+}
+
 // GetPermissionMenuIdsByUserId 获取用户菜单权限
 func (casbinService *SysCasbinService) GetPermissionMenuIdsByUserId(userId uint64) ([]uint64, error) {
 
@@ -100,6 +189,44 @@ func (casbinService *SysCasbinService) AddRolesForUser(userId uint64, roleIds []
 	return nil
 }
 
+// AddUsersForRole 批量为用户添加角色，已存在的关联会被跳过
+func (casbinService *SysCasbinService) AddUsersForRole(roleId uint64, userIds []uint64) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	roleStr := casbinService.GetCasbinRoleStr(roleId)
+	rules := make([][]string, 0, len(userIds))
+	for i := range userIds {
+		rules = append(rules, []string{casbinService.GetCasbinUserStr(userIds[i]), roleStr})
+	}
+	_, err := global.Enforcer.AddGroupingPoliciesEx(rules)
+
+	return err
+}
+
+// DeleteUsersForRole 批量删除用户的角色
+func (casbinService *SysCasbinService) DeleteUsersForRole(roleId uint64, userIds []uint64) error {
+
+	roleStr := casbinService.GetCasbinRoleStr(roleId)
+	rules := make([][]string, 0, len(userIds))
+	for i := range userIds {
+		rule := []string{casbinService.GetCasbinUserStr(userIds[i]), roleStr}
+		// 批量删除时存在不存在的规则会导致整体删除失败，需先过滤
+		if has, err := global.Enforcer.HasGroupingPolicy(rule); err != nil {
+			return err
+		} else if has {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	_, err := global.Enforcer.RemoveGroupingPolicies(rules)
+
+	return err
+}
+
 // GetPermissionByUserId 获取用户所有权限
 func (casbinService *SysCasbinService) GetPermissionByUserId(userId uint64) (map[string]any, error) {
 
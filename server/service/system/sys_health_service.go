@@ -0,0 +1,86 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/response/system"
+	"gorm.io/gorm"
+	"time"
+)
+
+// 就绪检查中单个依赖的超时时间，避免依赖挂起导致探针无响应
+const readinessTimeout = 2 * time.Second
+
+// 就绪检查依赖的表结构，用于判断数据库脚本是否已执行到最新版本
+var readinessSchema = []struct {
+	model   any
+	columns []string
+}{
+	{&model.SysRole{}, []string{"version", "created_by", "updated_by", "parent_id"}},
+	{&model.SysUser{}, []string{"version", "created_by", "updated_by", "token_version"}},
+	{&model.SysMenu{}, []string{"version", "created_by", "updated_by"}},
+	{&model.SysOperationLog{}, nil},
+	{&model.SysRoleHistory{}, nil},
+}
+
+type SysHealthService struct{}
+
+// Readiness 检查数据库、表结构和redis是否可用
+func (*SysHealthService) Readiness(ctx context.Context) *system.ReadinessResp {
+
+	failed := make(map[string]string)
+	if err := checkDatabase(ctx); err != nil {
+		failed["database"] = err.Error()
+	} else if err = checkSchema(ctx); err != nil {
+		failed["schema"] = err.Error()
+	}
+	if err := checkRedis(ctx); err != nil {
+		failed["redis"] = err.Error()
+	}
+
+	return &system.ReadinessResp{Ready: len(failed) == 0, Failed: failed}
+}
+
+func checkDatabase(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	sqlDB, err := global.GormDB.WithContext(ctx).DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func checkSchema(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	db := global.GormDB.WithContext(ctx)
+	migrator := db.Migrator()
+	for _, schema := range readinessSchema {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(schema.model); err != nil {
+			return err
+		}
+		if !migrator.HasTable(schema.model) {
+			return fmt.Errorf("table %s not found", stmt.Table)
+		}
+		for _, column := range schema.columns {
+			if !migrator.HasColumn(schema.model, column) {
+				return fmt.Errorf("column %s.%s not found", stmt.Table, column)
+			}
+		}
+	}
+	// HasTable 等方法会吞掉查询错误，超时后需要单独判断
+	return ctx.Err()
+}
+
+func checkRedis(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	return global.RedisCli.Ping(ctx).Err()
+}
@@ -48,7 +48,9 @@ func (*SysMenuService) AddMenu(req *common.Request) (uint64, error) {
 	return menu.Id, nil
 }
 
-func (*SysMenuService) EditMenu(req *common.Request) (uint64, error) {
+// EditMenu 编辑菜单，菜单的权限标识、状态或路由名称变更后角色的权限随之变化，
+// 需重新同步绑定该菜单的角色的casbin权限并使角色缓存失效
+func (service *SysMenuService) EditMenu(req *common.Request) (uint64, error) {
 
 	var menu model.SysMenu
 	err := copier.Copy(&menu, req.Data)
@@ -56,16 +58,54 @@ func (*SysMenuService) EditMenu(req *common.Request) (uint64, error) {
 		return 0, err
 	}
 
-	if err = global.GormDB.WithContext(req.Context).Where("id =?", menu.Id).Updates(&menu).Error; err != nil {
+	err = global.GormDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.WithContext(req.Context).Where("id =?", menu.Id).Updates(&menu).Error; err != nil {
+			return err
+		}
+		// 禁用菜单时状态为零值，Updates 不会更新零值字段，需单独更新
+		return tx.WithContext(req.Context).Model(&model.SysMenu{}).Where("id = ?", menu.Id).Update("status", menu.Status).Error
+	})
+	if err != nil {
 		return 0, err
 	}
 
+	if err = service.syncMenuRolePolicies(global.GormDB, menu.Id); err != nil {
+		return 0, err
+	}
+
+	// 角色的菜单已变更，角色缓存失效
+	CachedRole.Invalidate(req.Context)
 	return menu.Id, nil
 }
 
+// syncMenuRolePolicies 重新同步绑定该菜单的角色的casbin权限
+func (*SysMenuService) syncMenuRolePolicies(db *gorm.DB, menuId uint64) error {
+
+	var roleIds []uint64
+	if err := db.Model(&model.SysRoleMenu{}).Where("sys_menu_id = ?", menuId).Pluck("sys_role_id", &roleIds).Error; err != nil {
+		return err
+	}
+	// 同步时按菜单id比较，需先删除该菜单原有的权限标识对应的策略
+	if err := CasbinService.DeletePermissionByMenuId(menuId); err != nil {
+		return err
+	}
+
+	for _, roleId := range roleIds {
+		var menus []model.SysMenu
+		roleMenuIds := db.Model(&model.SysRoleMenu{}).Select("sys_menu_id").Where("sys_role_id = ?", roleId)
+		if err := db.Model(&model.SysMenu{}).Select("id, permission").Where("id IN (?)", roleMenuIds).Find(&menus).Error; err != nil {
+			return err
+		}
+		if err := RoleService.syncMenuPolicies(roleId, menus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (*SysMenuService) DeleteMenu(req *common.Request) error {
 
-	menuId := req.Data.(*request.SysMenuUpdateReq).Id
+	menuId := req.Data.(*request.QueryIdReq).Id
 
 	var count int64
 	if err := global.GormDB.Model(&model.SysMenu{}).Where("parent_id = ?", menuId).Count(&count).Error; err != nil {
@@ -75,9 +115,16 @@ func (*SysMenuService) DeleteMenu(req *common.Request) error {
 		return buserr.NewNoticeBusErr("菜单下有子菜单，不能被删除")
 	}
 
-	return global.GormDB.Transaction(func(tx *gorm.DB) error {
-		// 删除casbin权限
-		if err := CasbinService.DeletePermissionByMenuId(menuId); err != nil {
+	err := global.GormDB.Transaction(func(tx *gorm.DB) error {
+		// 删除角色与菜单的关联，并保存菜单变更后角色的历史版本
+		var roleIds []uint64
+		if err := tx.Model(&model.SysRoleMenu{}).Where("sys_menu_id = ?", menuId).Pluck("sys_role_id", &roleIds).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("sys_menu_id = ?", menuId).Delete(&model.SysRoleMenu{}).Error; err != nil {
+			return err
+		}
+		if err := recordRoleHistory(req.Context, tx, model.OperationUpdate, roleIds...); err != nil {
 			return err
 		}
 
@@ -87,7 +134,18 @@ func (*SysMenuService) DeleteMenu(req *common.Request) error {
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
 
+	// 删除casbin权限，casbin 的策略不随事务回滚，菜单删除成功后再删除
+	if err = CasbinService.DeletePermissionByMenuId(menuId); err != nil {
+		return err
+	}
+
+	// 角色的菜单已变更，角色缓存失效
+	CachedRole.Invalidate(req.Context)
+	return nil
 }
 
 // GetSelfPermissionRouters 获取自身权限路由
@@ -141,8 +199,7 @@ func (*SysMenuService) AllSimpleMenuTree() (res []*response.SysSimpleMenuResp, e
 // ListMenusByRoleId 获取角色拥有的菜单
 func (*SysMenuService) ListMenusByRoleId(req *common.Request) (menuIds []uint64, err error) {
 	roleId := req.Data.(*request.QueryIdReq).Id
-	menuIds, err = CasbinService.GetPermissionMenuIdsByRoleId(roleId)
-	return
+	return RoleService.GetMenuIdsByRole(req.Context, roleId)
 }
 
 // buildPermissionRoutes 构建权限路由
@@ -0,0 +1,65 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"slices"
+	"testing"
+)
+
+func TestEditMenuRefreshesRolePermissions(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	oldCachedRole := CachedRole
+	CachedRole = NewCachedRoleService(RoleService, cache.NewMemoryCache(), 0)
+	t.Cleanup(func() { CachedRole = oldCachedRole })
+
+	menu := model.SysMenu{Name: "用户列表", RouteName: "user", Type: 3, Permission: "sys:user:list", Status: 1}
+	db.Create(&menu)
+	role := model.SysRole{Name: "运维", Code: "ops", Status: 1}
+	db.Create(&role)
+	user := model.SysUser{Username: "u1", Roles: []model.SysRole{role}}
+	db.Create(&user)
+	if err := RoleService.AssignMenus(ctx, role.Id, []uint64{menu.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := CachedRole.GetUserPermissions(ctx, user.Id)
+	if err != nil || !slices.Contains(perms.Permissions, "sys:user:list") {
+		t.Fatalf("permissions before edit = %+v, err %v", perms, err)
+	}
+
+	edit := &request.SysMenuUpdateReq{Id: menu.Id, SysMenuAddReq: request.SysMenuAddReq{
+		Name: menu.Name, RouteName: menu.RouteName, Type: menu.Type, Permission: "sys:user:query", Status: 1,
+	}}
+	if _, err = MenuService.EditMenu(&common.Request{Data: edit, Context: ctx}); err != nil {
+		t.Fatalf("EditMenu: %v", err)
+	}
+
+	// 角色的casbin策略使用新的权限标识
+	roleStr := CasbinService.GetCasbinRoleStr(role.Id)
+	if ok, _ := CasbinService.Enforce(roleStr, "sys:user:query"); !ok {
+		t.Fatal("role is not granted the new permission")
+	}
+	if ok, _ := CasbinService.Enforce(roleStr, "sys:user:list"); ok {
+		t.Fatal("role still has the old permission")
+	}
+
+	// 缓存已失效，不再返回旧的权限标识
+	if perms, _ = CachedRole.GetUserPermissions(ctx, user.Id); !slices.Equal(perms.Permissions, []string{"sys:user:query"}) {
+		t.Fatalf("permissions after edit = %v, want [sys:user:query]", perms.Permissions)
+	}
+
+	// 禁用菜单后不再返回该菜单的权限
+	edit.Status = 0
+	if _, err = MenuService.EditMenu(&common.Request{Data: edit, Context: ctx}); err != nil {
+		t.Fatalf("EditMenu: %v", err)
+	}
+	if perms, _ = CachedRole.GetUserPermissions(ctx, user.Id); len(perms.Permissions) != 0 {
+		t.Fatalf("permissions after disabling menu = %v, want none", perms.Permissions)
+	}
+}
@@ -0,0 +1,250 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"gitee.com/nichanghao/gdmin/web/response"
+	"go.uber.org/zap"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	// 角色缓存版本号，角色或角色菜单变更时递增，使所有角色缓存一次性失效
+	roleCacheVersionKey = "sys:role:cache-version"
+
+	// 未配置过期时间时的默认值
+	defaultRoleCacheTTL = 10 * time.Minute
+)
+
+var (
+	CachedRole = NewCachedRoleService(RoleService, cache.NewRedisCache(), 0)
+)
+
+// CachedRoleService 带缓存的角色服务，缓存角色的有效菜单和按编码查询的角色。
+// 缓存key中包含版本号，角色变更时只需递增版本号即可使全部缓存失效，旧的缓存依赖过期时间清理
+type CachedRoleService struct {
+	*SysRoleService
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewCachedRoleService 创建带缓存的角色服务，ttl 为 0 时使用配置中的过期时间
+func NewCachedRoleService(roleService *SysRoleService, c cache.Cache, ttl time.Duration) *CachedRoleService {
+	return &CachedRoleService{SysRoleService: roleService, cache: c, ttl: ttl}
+}
+
+// GetEffectiveMenuIds 获取角色的有效菜单id，优先从缓存中获取
+func (s *CachedRoleService) GetEffectiveMenuIds(ctx context.Context, roleId uint64) ([]uint64, error) {
+
+	key := s.cacheKey(ctx, "effective-menus:"+strconv.FormatUint(roleId, 10))
+
+	var menuIds []uint64
+	if s.getCache(ctx, key, &menuIds) {
+		return menuIds, nil
+	}
+
+	menuIds, err := s.SysRoleService.GetEffectiveMenuIds(ctx, roleId)
+	if err != nil {
+		return nil, err
+	}
+	s.setCache(ctx, key, menuIds)
+	return menuIds, nil
+}
+
+// GetRoleByCode 根据编码查询角色，优先从缓存中获取
+func (s *CachedRoleService) GetRoleByCode(ctx context.Context, code string) (*model.SysRole, error) {
+
+	key := s.cacheKey(ctx, "code:"+normalizeRoleCode(code))
+
+	var role model.SysRole
+	if s.getCache(ctx, key, &role) {
+		return &role, nil
+	}
+
+	res, err := s.SysRoleService.GetRoleByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	s.setCache(ctx, key, res)
+	return res, nil
+}
+
+// GetUserPermissions 获取用户拥有的权限，优先从缓存中获取。
+// 缓存key包含用户的令牌版本号，用户角色变更后令牌版本号递增，角色或菜单变更后缓存版本号递增，旧的缓存均不再使用
+func (s *CachedRoleService) GetUserPermissions(ctx context.Context, userId uint64) (*response.SysUserPermissionsResp, error) {
+
+	tokenVersion, err := cache.SysUserCache.GetSysUserTokenVersion(userId)
+	if err != nil {
+		return nil, err
+	}
+	key := s.cacheKey(ctx, "permissions:"+strconv.FormatUint(userId, 10)+":"+strconv.Itoa(tokenVersion))
+
+	var res response.SysUserPermissionsResp
+	if s.getCache(ctx, key, &res) {
+		return &res, nil
+	}
+
+	permissions, err := s.SysRoleService.GetUserPermissions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	s.setCache(ctx, key, permissions)
+	return permissions, nil
+}
+
+// AddRole 新增角色并使角色缓存失效
+func (s *CachedRoleService) AddRole(req *common.Request) error {
+	return s.invalidateAfter(req.Context, s.SysRoleService.AddRole(req))
+}
+
+// EditRole 编辑角色并使角色缓存失效
+func (s *CachedRoleService) EditRole(req *common.Request) error {
+	return s.invalidateAfter(req.Context, s.SysRoleService.EditRole(req))
+}
+
+// DeleteRole 删除角色并使角色缓存失效，预览时缓存不失效
+func (s *CachedRoleService) DeleteRole(req *common.Request) (*response.SysRoleDeleteResp, error) {
+
+	res, err := s.SysRoleService.DeleteRole(req)
+	if err == nil && !res.DryRun {
+		_ = s.invalidateAfter(req.Context, err)
+	}
+	return res, err
+}
+
+// DeleteRoles 批量删除角色并使角色缓存失效，预览时缓存不失效
+func (s *CachedRoleService) DeleteRoles(ctx context.Context, ids []uint64, force, dryRun bool) (*response.SysRoleDeleteResp, map[uint64]error, error) {
+
+	res, errs, err := s.SysRoleService.DeleteRoles(ctx, ids, force, dryRun)
+	if err == nil && !dryRun && res.Deleted > 0 {
+		_ = s.invalidateAfter(ctx, err)
+	}
+	return res, errs, err
+}
+
+// CloneRole 复制角色并使角色缓存失效
+func (s *CachedRoleService) CloneRole(ctx context.Context, srcId uint64, newName, newCode string) (*model.SysRole, error) {
+
+	role, err := s.SysRoleService.CloneRole(ctx, srcId, newName, newCode)
+	return role, s.invalidateAfter(ctx, err)
+}
+
+// PatchRole 局部修改角色并使角色缓存失效
+func (s *CachedRoleService) PatchRole(ctx context.Context, patch *request.SysRolePatchReq) error {
+	return s.invalidateAfter(ctx, s.SysRoleService.PatchRole(ctx, patch))
+}
+
+// SwapRoleCodes 交换两个角色的编码并使角色缓存失效
+func (s *CachedRoleService) SwapRoleCodes(ctx context.Context, idA, idB uint64) error {
+	return s.invalidateAfter(ctx, s.SysRoleService.SwapRoleCodes(ctx, idA, idB))
+}
+
+// RestoreRole 恢复角色并使角色缓存失效
+func (s *CachedRoleService) RestoreRole(req *common.Request) error {
+	return s.invalidateAfter(req.Context, s.SysRoleService.RestoreRole(req))
+}
+
+// ImportRoles 导入角色并使角色缓存失效
+func (s *CachedRoleService) ImportRoles(ctx context.Context, r io.Reader, strict bool) (int, []response.RowError, error) {
+
+	imported, errs, err := s.SysRoleService.ImportRoles(ctx, r, strict)
+	if imported > 0 {
+		_ = s.invalidateAfter(ctx, err)
+	}
+	return imported, errs, err
+}
+
+// ImportRolesJson 导入json格式的角色并使角色缓存失效
+func (s *CachedRoleService) ImportRolesJson(ctx context.Context, data []byte, opts request.SysRoleImportJsonReq) (*response.SysRoleJsonImportResp, error) {
+
+	res, err := s.SysRoleService.ImportRolesJson(ctx, data, opts)
+	return res, s.invalidateAfter(ctx, err)
+}
+
+// AssignRoleMenus 分配角色菜单并使角色缓存失效
+func (s *CachedRoleService) AssignRoleMenus(req *common.Request) error {
+	return s.invalidateAfter(req.Context, s.SysRoleService.AssignRoleMenus(req))
+}
+
+// AssignMenus 分配角色菜单并使角色缓存失效
+func (s *CachedRoleService) AssignMenus(ctx context.Context, roleId uint64, menuIds []uint64) error {
+	return s.invalidateAfter(ctx, s.SysRoleService.AssignMenus(ctx, roleId, menuIds))
+}
+
+// Seed 初始化内置数据并使角色缓存失效
+func (s *CachedRoleService) Seed(ctx context.Context) error {
+	return s.invalidateAfter(ctx, s.SysRoleService.Seed(ctx))
+}
+
+// Invalidate 递增缓存版本号，使所有角色缓存失效
+func (s *CachedRoleService) Invalidate(ctx context.Context) {
+
+	if _, err := s.cache.Incr(ctx, roleCacheVersionKey); err != nil {
+		zap.L().Error("Invalidate role cache error: ", zap.Error(err))
+	}
+}
+
+// invalidateAfter 操作成功且事务提交后使角色缓存失效
+func (s *CachedRoleService) invalidateAfter(ctx context.Context, err error) error {
+	if err == nil {
+		common.AfterCommit(ctx, func() { s.Invalidate(ctx) })
+	}
+	return err
+}
+
+// cacheKey 生成带版本号的缓存key
+func (s *CachedRoleService) cacheKey(ctx context.Context, key string) string {
+
+	version, _, err := s.cache.Get(ctx, roleCacheVersionKey)
+	if err != nil {
+		zap.L().Error("Get role cache version error: ", zap.Error(err))
+	}
+	if version == "" {
+		version = "0"
+	}
+	return "sys:role:v" + version + ":" + key
+}
+
+// getCache 获取缓存并反序列化至 dest，缓存不存在或读取失败时返回 false
+func (s *CachedRoleService) getCache(ctx context.Context, key string, dest any) bool {
+
+	value, ok, err := s.cache.Get(ctx, key)
+	if err != nil {
+		zap.L().Error("Get role cache error: ", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	if !ok {
+		return false
+	}
+	return json.Unmarshal([]byte(value), dest) == nil
+}
+
+// setCache 序列化并写入缓存，写入失败不影响业务
+func (s *CachedRoleService) setCache(ctx context.Context, key string, value any) {
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err = s.cache.Set(ctx, key, string(data), s.expiration()); err != nil {
+		zap.L().Error("Set role cache error: ", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// expiration 获取缓存过期时间
+func (s *CachedRoleService) expiration() time.Duration {
+	if s.ttl > 0 {
+		return s.ttl
+	}
+	if global.Config != nil && global.Config.Cache.RoleTTL > 0 {
+		return time.Duration(global.Config.Cache.RoleTTL) * time.Second
+	}
+	return defaultRoleCacheTTL
+}
@@ -0,0 +1,80 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/model"
+	"gorm.io/gorm"
+)
+
+// CloneRole 以已有角色为模板创建新角色，复制备注、状态、数据权限范围、父角色及绑定的菜单，不复制用户关联。
+// 所有数据在同一事务中写入，复制失败时不会留下不完整的角色
+func (roleService *SysRoleService) CloneRole(ctx context.Context, srcId uint64, newName, newCode string) (*model.SysRole, error) {
+
+	var role model.SysRole
+	var policies policyChanges
+	err := common.ModelDB(ctx, &model.SysRole{}).Transaction(func(tx *gorm.DB) error {
+
+		var src model.SysRole
+		if errors.Is(tx.Where("id = ?", srcId).First(&src).Error, gorm.ErrRecordNotFound) {
+			return buserr.ErrRoleNotFound
+		}
+
+		role = model.SysRole{
+			Name:      newName,
+			Code:      normalizeRoleCode(newCode),
+			Status:    src.Status,
+			Desc:      src.Desc,
+			DataScope: src.DataScope,
+			ParentId:  src.ParentId,
+		}
+		if err := role.Validate(); err != nil {
+			return err
+		}
+		if err := roleService.authorize(ctx, &role, RoleActionCreate); err != nil {
+			return err
+		}
+		if err := roleService.validateDuplicateRole(tx, &role); err != nil {
+			return err
+		}
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+
+		// 复制自定义数据权限的部门
+		var deptIds []uint64
+		if err := tx.Model(&model.SysRoleDept{}).Where("sys_role_id = ?", src.Id).Pluck("sys_dept_id", &deptIds).Error; err != nil {
+			return err
+		}
+		if err := roleService.assignRoleDepts(tx, &role, deptIds); err != nil {
+			return err
+		}
+
+		// 复制绑定的菜单并同步casbin权限
+		var menus []model.SysMenu
+		menuIds := tx.Model(&model.SysRoleMenu{}).Select("sys_menu_id").Where("sys_role_id = ?", src.Id)
+		if err := tx.Model(&model.SysMenu{}).Select("id, permission").Where("id IN (?)", menuIds).Find(&menus).Error; err != nil {
+			return err
+		}
+		if err := roleService.bindRoleMenus(tx, role.Id, menus, &policies); err != nil {
+			return err
+		}
+		if err := recordRoleHistory(ctx, tx, model.OperationCreate, role.Id); err != nil {
+			return err
+		}
+
+		policies.add(func() error { return CasbinService.SetRoleParent(role.Id, role.ParentId) })
+		return nil
+	})
+	if err != nil {
+		return nil, translateRoleError(err)
+	}
+
+	policies.applyAfterCommit(ctx)
+	AuditService.RecordRole(ctx, model.OperationCreate, nil, &role)
+	publishRoleEvent(ctx, event.RoleCreated{RoleEvent: newRoleEvent(ctx, &role)})
+	return &role, nil
+}
@@ -0,0 +1,132 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// recordRoleHistory 保存角色当前的完整数据作为新的修订，需在角色变更的事务中调用，使历史与数据一致。
+// 修订号为角色已有的最大修订号加一，角色行在变更时已被当前事务锁定，同一角色的修订不会并发写入
+func recordRoleHistory(ctx context.Context, tx *gorm.DB, action string, roleIds ...uint64) error {
+
+	if len(roleIds) == 0 {
+		return nil
+	}
+
+	// 1. 查询角色及其绑定的菜单和部门，删除的角色同样需要保存
+	var roles []model.SysRole
+	if err := tx.Unscoped().Model(&model.SysRole{}).Where("id IN ?", roleIds).Find(&roles).Error; err != nil {
+		return err
+	}
+	var roleMenus []model.SysRoleMenu
+	if err := tx.Model(&model.SysRoleMenu{}).Where("sys_role_id IN ?", roleIds).Order("sys_menu_id").Find(&roleMenus).Error; err != nil {
+		return err
+	}
+	var roleDepts []model.SysRoleDept
+	if err := tx.Model(&model.SysRoleDept{}).Where("sys_role_id IN ?", roleIds).Order("sys_dept_id").Find(&roleDepts).Error; err != nil {
+		return err
+	}
+
+	// 2. 查询角色当前的修订号
+	var latest []model.SysRoleHistory
+	if err := tx.Model(&model.SysRoleHistory{}).Select("role_id, MAX(revision) AS revision").
+		Where("role_id IN ?", roleIds).Group("role_id").Find(&latest).Error; err != nil {
+		return err
+	}
+	revisions := make(map[uint64]uint64, len(latest))
+	for i := range latest {
+		revisions[latest[i].RoleId] = latest[i].Revision
+	}
+
+	// 3. 生成快照
+	snapshots := make(map[uint64]*model.SysRoleSnapshot, len(roles))
+	for i := range roles {
+		snapshots[roles[i].Id] = newRoleSnapshot(&roles[i])
+	}
+	for i := range roleMenus {
+		snapshot := snapshots[roleMenus[i].SysRoleId]
+		snapshot.MenuIds = append(snapshot.MenuIds, roleMenus[i].SysMenuId)
+	}
+	for i := range roleDepts {
+		snapshot := snapshots[roleDepts[i].SysRoleId]
+		snapshot.DeptIds = append(snapshot.DeptIds, roleDepts[i].SysDeptId)
+	}
+
+	userId := common.USER_CTX.GetUserId(&ctx)
+	histories := make([]model.SysRoleHistory, 0, len(roles))
+	for i := range roles {
+		histories = append(histories, model.SysRoleHistory{
+			RoleId:   roles[i].Id,
+			Revision: revisions[roles[i].Id] + 1,
+			Action:   action,
+			Snapshot: snapshots[roles[i].Id],
+			UserId:   userId,
+		})
+	}
+	return tx.Model(&model.SysRoleHistory{}).Create(&histories).Error
+}
+
+// newRoleSnapshot 根据角色生成快照，菜单和部门为空时保存为空数组
+func newRoleSnapshot(role *model.SysRole) *model.SysRoleSnapshot {
+
+	snapshot := &model.SysRoleSnapshot{
+		Id:        role.Id,
+		Name:      role.Name,
+		Code:      role.Code,
+		Status:    role.Status,
+		Desc:      role.Desc,
+		DataScope: role.DataScope,
+		ParentId:  role.ParentId,
+		MenuIds:   make([]uint64, 0),
+		DeptIds:   make([]uint64, 0),
+		Version:   role.Version,
+		CreatedAt: role.CreatedAt,
+		UpdatedAt: role.UpdatedAt,
+		CreatedBy: role.CreatedBy,
+		UpdatedBy: role.UpdatedBy,
+	}
+	if role.DeletedAt.Valid {
+		deletedAt := role.DeletedAt.Time
+		snapshot.DeletedAt = &deletedAt
+	}
+	return snapshot
+}
+
+// ListRoleRevisions 获取角色的修订列表，按修订号正序排列，不包含角色数据快照
+func (*SysAuditService) ListRoleRevisions(ctx context.Context, roleId uint64) ([]*model.SysRoleHistory, error) {
+
+	histories := make([]*model.SysRoleHistory, 0)
+	err := common.DBFromContext(ctx).Model(&model.SysRoleHistory{}).
+		Select("id, role_id, revision, action, user_id, created_at").
+		Where("role_id = ?", roleId).Order("revision ASC").Find(&histories).Error
+	return histories, err
+}
+
+// GetRoleAtRevision 获取角色在指定修订时的完整数据
+func (*SysAuditService) GetRoleAtRevision(ctx context.Context, roleId, revision uint64) (*model.SysRoleHistory, error) {
+
+	var history model.SysRoleHistory
+	err := common.DBFromContext(ctx).Model(&model.SysRoleHistory{}).
+		Where("role_id = ? AND revision = ?", roleId, revision).Take(&history).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, buserr.ErrRoleRevisionNotFound
+	}
+	return &history, err
+}
+
+// GetRoleAt 获取角色在指定时间的完整数据，即该时间之前的最后一个修订
+func (*SysAuditService) GetRoleAt(ctx context.Context, roleId uint64, at time.Time) (*model.SysRoleHistory, error) {
+
+	var history model.SysRoleHistory
+	err := common.DBFromContext(ctx).Model(&model.SysRoleHistory{}).
+		Where("role_id = ? AND created_at <= ?", roleId, at).Order("revision DESC").Take(&history).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, buserr.ErrRoleRevisionNotFound
+	}
+	return &history, err
+}
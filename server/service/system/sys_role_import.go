@@ -0,0 +1,200 @@
+package system
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/response"
+	mapset "github.com/deckarep/golang-set/v2"
+	"gorm.io/gorm"
+	"io"
+	"strings"
+)
+
+// roleImportRow 待导入的角色数据
+type roleImportRow struct {
+	row  int
+	role model.SysRole
+}
+
+// ImportRoles 从csv导入角色，csv列依次为 name,code,desc，首行为表头时自动跳过。
+// 校验通过的行在同一事务中写入，strict 为 true 时只要存在校验失败的行就取消整个导入
+func (roleService *SysRoleService) ImportRoles(ctx context.Context, r io.Reader, strict bool) (imported int, errs []response.RowError, err error) {
+
+	locale := i18n.LocaleFrom(ctx)
+	rows, errs, err := roleService.parseImportCsv(r, locale)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var roles []model.SysRole
+	err = common.DBFromContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// 校验角色名称和编码是否已存在
+		rows, errs, err = roleService.validateImportRows(tx, rows, errs, locale)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) == 0 || (strict && len(errs) > 0) {
+			return nil
+		}
+
+		roles = make([]model.SysRole, 0, len(rows))
+		for i := range rows {
+			if err = roleService.authorize(ctx, &rows[i].role, RoleActionCreate); err != nil {
+				return err
+			}
+			roles = append(roles, rows[i].role)
+		}
+		if err = tx.Create(&roles).Error; err != nil {
+			return err
+		}
+		roleIds := make([]uint64, 0, len(roles))
+		for i := range roles {
+			roleIds = append(roleIds, roles[i].Id)
+		}
+		if err = recordRoleHistory(ctx, tx, model.OperationCreate, roleIds...); err != nil {
+			return err
+		}
+		for i := range roles {
+			if err = AuditService.RecordRoleTx(ctx, tx, model.OperationCreate, nil, &roles[i]); err != nil {
+				return err
+			}
+		}
+
+		imported = len(roles)
+		return nil
+	})
+	if err != nil {
+		return 0, errs, err
+	}
+
+	for i := range roles {
+		publishRoleEvent(ctx, event.RoleCreated{RoleEvent: newRoleEvent(ctx, &roles[i])})
+	}
+	return imported, errs, nil
+}
+
+// parseImportCsv 解析csv并校验每一行的字段，失败原因按请求的语言翻译
+func (*SysRoleService) parseImportCsv(r io.Reader, locale string) (rows []roleImportRow, errs []response.RowError, err error) {
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	codes := mapset.NewThreadUnsafeSet[string]()
+	names := mapset.NewThreadUnsafeSet[string]()
+	for line := 1; ; line++ {
+		record, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, nil, buserr.NewNoticeBusErr("解析csv文件失败：" + readErr.Error())
+		}
+
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		if line == 1 {
+			// 去除excel导出时携带的BOM
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if strings.EqualFold(record[0], "name") {
+				continue
+			}
+		}
+
+		if len(record) < 2 {
+			errs = append(errs, importRowError(locale, line, i18n.RoleImportColumnMissing))
+			continue
+		}
+		role := model.SysRole{Name: record[0], Code: normalizeRoleCode(record[1])}
+		if len(record) > 2 {
+			role.Desc = record[2]
+		}
+
+		if msg := validateImportRole(&role, locale); msg != "" {
+			errs = append(errs, response.RowError{Row: line, Message: msg})
+			continue
+		}
+		if !names.Add(role.Name) {
+			errs = append(errs, importRowError(locale, line, i18n.RoleImportNameRepeated))
+			continue
+		}
+		if !codes.Add(role.Code) {
+			errs = append(errs, importRowError(locale, line, i18n.RoleImportCodeRepeated))
+			continue
+		}
+
+		rows = append(rows, roleImportRow{row: line, role: role})
+	}
+
+	return rows, errs, nil
+}
+
+// validateImportRows 校验角色名称和编码在数据库中是否已存在，返回校验通过的行
+func (*SysRoleService) validateImportRows(tx *gorm.DB, rows []roleImportRow, errs []response.RowError, locale string) ([]roleImportRow, []response.RowError, error) {
+	if len(rows) == 0 {
+		return rows, errs, nil
+	}
+
+	names := make([]string, 0, len(rows))
+	codes := make([]string, 0, len(rows))
+	for i := range rows {
+		names = append(names, rows[i].role.Name)
+		codes = append(codes, rows[i].role.Code)
+	}
+
+	var existNames, existCodes []string
+	if err := tx.Model(&model.SysRole{}).Where("name IN ?", names).Pluck("name", &existNames).Error; err != nil {
+		return nil, nil, err
+	}
+	// 导入的编码已规范化为小写，与数据库中的编码比较时忽略大小写
+	if err := tx.Model(&model.SysRole{}).Where("LOWER(code) IN ?", codes).Pluck("LOWER(code)", &existCodes).Error; err != nil {
+		return nil, nil, err
+	}
+	existNameSet := mapset.NewThreadUnsafeSet(existNames...)
+	existCodeSet := mapset.NewThreadUnsafeSet(existCodes...)
+
+	validRows := make([]roleImportRow, 0, len(rows))
+	for i := range rows {
+		switch {
+		case existNameSet.Contains(rows[i].role.Name):
+			errs = append(errs, importRowError(locale, rows[i].row, i18n.RoleNameExists))
+		case existCodeSet.Contains(rows[i].role.Code):
+			errs = append(errs, importRowError(locale, rows[i].row, i18n.RoleCodeExists))
+		default:
+			validRows = append(validRows, rows[i])
+		}
+	}
+
+	return validRows, errs, nil
+}
+
+// validateImportRole 校验角色字段，返回按语言翻译的校验失败的原因
+func validateImportRole(role *model.SysRole, locale string) string {
+	if role.Name == "" {
+		message, _ := i18n.Translate(locale, i18n.RoleNameRequired)
+		return message
+	}
+	if err := role.Validate(); err != nil {
+		var validErr *buserr.ValidationError
+		if errors.As(err, &validErr) {
+			return validErr.Localize(locale).Error()
+		}
+		return err.Error()
+	}
+	return ""
+}
+
+// importRowError 按语言翻译导入失败的行的原因
+func importRowError(locale string, row int, key string) response.RowError {
+	message, _ := i18n.Translate(locale, key)
+	return response.RowError{Row: row, Message: message}
+}
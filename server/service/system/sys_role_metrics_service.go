@@ -0,0 +1,85 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/metrics"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"gitee.com/nichanghao/gdmin/web/response"
+	"time"
+)
+
+var (
+	MetricsRole = NewMetricsRoleService(CachedRole, metrics.Role)
+)
+
+// MetricsRoleService 记录监控指标的角色服务，统计新增、修改、删除及列表查询的耗时和业务错误，不修改角色服务的逻辑
+type MetricsRoleService struct {
+	*CachedRoleService
+	collector *metrics.RoleCollector
+}
+
+// NewMetricsRoleService 创建记录监控指标的角色服务
+func NewMetricsRoleService(roleService *CachedRoleService, collector *metrics.RoleCollector) *MetricsRoleService {
+	return &MetricsRoleService{CachedRoleService: roleService, collector: collector}
+}
+
+// PageRoles 分页查询角色列表
+func (s *MetricsRoleService) PageRoles(ctx context.Context, req *request.SysRolePageReq, opts ...QueryOption) (res *common.PageResp, err error) {
+	defer s.observe(metrics.OpList, time.Now(), &err)
+	return s.CachedRoleService.PageRoles(ctx, req, opts...)
+}
+
+// CursorRoles 游标分页查询角色列表
+func (s *MetricsRoleService) CursorRoles(ctx context.Context, req *request.SysRoleCursorReq) (res *common.CursorResp, err error) {
+	defer s.observe(metrics.OpList, time.Now(), &err)
+	return s.CachedRoleService.CursorRoles(ctx, req)
+}
+
+// AddRole 新增角色
+func (s *MetricsRoleService) AddRole(req *common.Request) (err error) {
+	defer s.observe(metrics.OpCreate, time.Now(), &err)
+	return s.CachedRoleService.AddRole(req)
+}
+
+// CloneRole 复制角色
+func (s *MetricsRoleService) CloneRole(ctx context.Context, srcId uint64, newName, newCode string) (role *model.SysRole, err error) {
+	defer s.observe(metrics.OpCreate, time.Now(), &err)
+	return s.CachedRoleService.CloneRole(ctx, srcId, newName, newCode)
+}
+
+// EditRole 编辑角色
+func (s *MetricsRoleService) EditRole(req *common.Request) (err error) {
+	defer s.observe(metrics.OpUpdate, time.Now(), &err)
+	return s.CachedRoleService.EditRole(req)
+}
+
+// PatchRole 局部修改角色
+func (s *MetricsRoleService) PatchRole(ctx context.Context, patch *request.SysRolePatchReq) (err error) {
+	defer s.observe(metrics.OpUpdate, time.Now(), &err)
+	return s.CachedRoleService.PatchRole(ctx, patch)
+}
+
+// SwapRoleCodes 交换两个角色的编码
+func (s *MetricsRoleService) SwapRoleCodes(ctx context.Context, idA, idB uint64) (err error) {
+	defer s.observe(metrics.OpUpdate, time.Now(), &err)
+	return s.CachedRoleService.SwapRoleCodes(ctx, idA, idB)
+}
+
+// DeleteRole 删除角色
+func (s *MetricsRoleService) DeleteRole(req *common.Request) (res *response.SysRoleDeleteResp, err error) {
+	defer s.observe(metrics.OpDelete, time.Now(), &err)
+	return s.CachedRoleService.DeleteRole(req)
+}
+
+// DeleteRoles 批量删除角色
+func (s *MetricsRoleService) DeleteRoles(ctx context.Context, ids []uint64, force, dryRun bool) (res *response.SysRoleDeleteResp, errs map[uint64]error, err error) {
+	defer s.observe(metrics.OpDelete, time.Now(), &err)
+	return s.CachedRoleService.DeleteRoles(ctx, ids, force, dryRun)
+}
+
+// observe 操作结束后记录耗时及错误，err 为指向命名返回值的指针
+func (s *MetricsRoleService) observe(op string, start time.Time, err *error) {
+	s.collector.Observe(op, start, *err)
+}
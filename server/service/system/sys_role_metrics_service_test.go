@@ -0,0 +1,100 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/metrics"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"strconv"
+	"testing"
+)
+
+func TestMetricsRoleServiceObservesAddRole(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+
+	collector := metrics.NewRoleCollector()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	roleService := NewMetricsRoleService(NewCachedRoleService(RoleService, cache.NewMemoryCache(), 0), collector)
+
+	addReq := &request.SysRoleAddReq{Name: "运维", Code: "ops"}
+	if err := roleService.AddRole(&common.Request{Data: addReq, Context: ctx}); err != nil {
+		t.Fatalf("AddRole: %v", err)
+	}
+	if count := opDurationCount(t, registry, metrics.OpCreate); count != 1 {
+		t.Fatalf("create duration sample count = %d, want 1", count)
+	}
+	if errCount := busErrorCount(t, registry, metrics.OpCreate, buserr.RoleCodeConflictCode); errCount != 0 {
+		t.Fatalf("business errors after success = %v, want 0", errCount)
+	}
+
+	// 重复的角色标识按业务错误码计数，耗时同样记录
+	addReq = &request.SysRoleAddReq{Name: "运维2", Code: "ops"}
+	if err := roleService.AddRole(&common.Request{Data: addReq, Context: ctx}); err == nil {
+		t.Fatal("AddRole with a duplicate code succeeded")
+	}
+	if count := opDurationCount(t, registry, metrics.OpCreate); count != 2 {
+		t.Fatalf("create duration sample count = %d, want 2", count)
+	}
+	if errCount := busErrorCount(t, registry, metrics.OpCreate, buserr.RoleCodeConflictCode); errCount != 1 {
+		t.Fatalf("role code conflict errors = %v, want 1", errCount)
+	}
+}
+
+// opDurationCount 获取操作耗时直方图的样本数
+func opDurationCount(t *testing.T, registry *prometheus.Registry, op string) uint64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "role_service_op_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if hasLabels(m.GetLabel(), "op", op) {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+// busErrorCount 获取操作按错误码统计的业务错误数量
+func busErrorCount(t *testing.T, registry *prometheus.Registry, op string, code int) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "role_service_business_errors_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if hasLabels(m.GetLabel(), "op", op, "code", strconv.Itoa(code)) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func hasLabels(labels []*dto.LabelPair, pairs ...string) bool {
+	values := make(map[string]string, len(labels))
+	for _, label := range labels {
+		values[label.GetName()] = label.GetValue()
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		if values[pairs[i]] != pairs[i+1] {
+			return false
+		}
+	}
+	return true
+}
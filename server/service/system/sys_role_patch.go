@@ -0,0 +1,151 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// PatchRole 局部修改角色，只更新请求中传入的字段，传入零值时更新为零值（如清空备注）。
+// 使用字段集合更新，不会修改角色关联的用户；传入版本号时进行乐观锁校验
+func (roleService *SysRoleService) PatchRole(ctx context.Context, patch *request.SysRolePatchReq) error {
+
+	if patch.Name != nil && *patch.Name == "" {
+		validErr := &buserr.ValidationError{}
+		validErr.AddMessage("name", i18n.RoleNameRequired)
+		return validErr
+	}
+
+	var roleOld, roleNew model.SysRole
+	var userIds []uint64
+	var policies policyChanges
+	err := common.ModelDB(ctx, &model.SysRole{}).Transaction(func(tx *gorm.DB) error {
+
+		if errors.Is(tx.Where("id = ?", patch.Id).First(&roleOld).Error, gorm.ErrRecordNotFound) {
+			return buserr.ErrRoleNotFound
+		}
+		if err := roleService.authorize(ctx, &roleOld, RoleActionUpdate); err != nil {
+			return err
+		}
+
+		// 合并后的角色用于校验，只有传入的字段写入数据库
+		role := roleOld
+		columns := make(map[string]any)
+		if patch.Name != nil && *patch.Name != roleOld.Name {
+			role.Name = *patch.Name
+			if err := roleService.validateDuplicateRoleByName(tx, role.Name); err != nil {
+				return err
+			}
+			columns["name"] = role.Name
+		}
+		// 历史数据中的编码可能不是小写，仅大小写不同时视为未修改
+		if patch.Code != nil && !strings.EqualFold(roleOld.Code, normalizeRoleCode(*patch.Code)) {
+			role.Code = normalizeRoleCode(*patch.Code)
+			if isReservedRole(&roleOld) {
+				return buserr.ErrReservedRole
+			}
+			if err := roleService.authorize(ctx, &role, RoleActionUpdate); err != nil {
+				return err
+			}
+			if err := roleService.validateDuplicateRoleByCode(tx, role.Code); err != nil {
+				return err
+			}
+			columns["code"] = role.Code
+		}
+		if patch.Status != nil {
+			role.Status = *patch.Status
+			columns["status"] = role.Status
+		}
+		if patch.Desc != nil {
+			role.Desc = *patch.Desc
+			columns["desc"] = role.Desc
+		}
+		if patch.DataScope != nil {
+			role.DataScope = *patch.DataScope
+			columns["data_scope"] = role.DataScope
+		}
+		changeParent := patch.ParentId != nil && *patch.ParentId != roleOld.ParentId
+		if changeParent {
+			role.ParentId = *patch.ParentId
+			if err := roleService.validateRoleParent(tx, role.Id, role.ParentId); err != nil {
+				return err
+			}
+			columns["parent_id"] = role.ParentId
+		}
+		if err := role.Validate(); err != nil {
+			return err
+		}
+
+		// 未传入任何字段时不修改数据，只修改自定义部门时也递增版本号
+		if len(columns) == 0 && patch.DeptIds == nil {
+			return nil
+		}
+		q := tx.WithContext(ctx).Where("id = ?", role.Id)
+		if patch.Version != nil {
+			q = q.Where("version = ?", *patch.Version)
+		}
+		columns["version"] = gorm.Expr("version + ?", 1)
+		if result := q.Updates(columns); result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return buserr.ErrStaleObject
+		}
+
+		if changeParent {
+			policies.add(func() error { return CasbinService.SetRoleParent(role.Id, role.ParentId) })
+		}
+
+		// 只修改数据权限范围时保留原有的自定义部门
+		if patch.DataScope != nil || patch.DeptIds != nil {
+			var deptIds []uint64
+			if patch.DeptIds != nil {
+				deptIds = *patch.DeptIds
+			} else if err := tx.Model(&model.SysRoleDept{}).Where("sys_role_id = ?", role.Id).
+				Pluck("sys_dept_id", &deptIds).Error; err != nil {
+				return err
+			}
+			if err := roleService.assignRoleDepts(tx, &role, deptIds); err != nil {
+				return err
+			}
+		}
+
+		// 查询修改后的数据用于记录操作日志
+		if err := tx.Where("id = ?", role.Id).First(&roleNew).Error; err != nil {
+			return err
+		}
+		if err := recordRoleHistory(ctx, tx, model.OperationUpdate, role.Id); err != nil {
+			return err
+		}
+
+		// 角色编码或状态变更后，token中携带的角色编码已过期，拥有该角色的用户需重新获取token
+		if roleOld.Code != roleNew.Code || roleOld.Status != roleNew.Status {
+			var err error
+			if userIds, err = roleUserIds(tx, role.Id); err != nil {
+				return err
+			}
+			return incrTokenVersion(tx, userIds)
+		}
+		return nil
+	})
+	if err != nil {
+		return translateRoleError(err)
+	}
+	// 未修改数据时不记录操作日志
+	if roleNew.Id == 0 {
+		return nil
+	}
+
+	policies.applyAfterCommit(ctx)
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	AuditService.RecordRole(ctx, model.OperationUpdate, &roleOld, &roleNew)
+	publishRoleEvent(ctx, event.RoleUpdated{RoleEvent: newRoleEvent(ctx, &roleNew)})
+	return nil
+}
@@ -0,0 +1,114 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"testing"
+)
+
+func TestPatchRole(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	role := model.SysRole{Name: "运维", Code: "ops", Desc: "运维人员", Status: 1, DataScope: model.DataScopeDept}
+	db.Create(&role)
+	db.Create(&model.SysUser{Username: "u1", Roles: []model.SysRole{role}})
+	getRole := func() model.SysRole {
+		var got model.SysRole
+		if err := db.First(&got, role.Id).Error; err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// 只传入名称时其他字段和关联的用户不变
+	name := "运维组"
+	if err := RoleService.PatchRole(ctx, &request.SysRolePatchReq{Id: role.Id, Name: &name}); err != nil {
+		t.Fatalf("PatchRole name: %v", err)
+	}
+	got := getRole()
+	if got.Name != name || got.Code != "ops" || got.Desc != "运维人员" || got.Status != 1 || got.DataScope != model.DataScopeDept {
+		t.Fatalf("after patching name = %+v", got)
+	}
+	if got.Version != role.Version+1 {
+		t.Fatalf("version = %d, want %d", got.Version, role.Version+1)
+	}
+	if count := db.Model(&got).Association("Users").Count(); count != 1 {
+		t.Fatalf("role users = %d after patch, want 1", count)
+	}
+
+	// 传入空字符串时清空备注
+	desc := ""
+	if err := RoleService.PatchRole(ctx, &request.SysRolePatchReq{Id: role.Id, Desc: &desc}); err != nil {
+		t.Fatalf("PatchRole desc: %v", err)
+	}
+	if got = getRole(); got.Desc != "" || got.Name != name || got.Code != "ops" {
+		t.Fatalf("after clearing desc = %+v", got)
+	}
+
+	// 名称不能为空，版本号不一致时不修改
+	var validErr *buserr.ValidationError
+	if err := RoleService.PatchRole(ctx, &request.SysRolePatchReq{Id: role.Id, Name: &desc}); !errors.As(err, &validErr) {
+		t.Fatalf("PatchRole with an empty name err = %v, want *buserr.ValidationError", err)
+	}
+	if msg := validErr.Localize("en").Error(); msg != "Role name is required" {
+		t.Fatalf("localized message = %q", msg)
+	}
+	staleVersion, newName := role.Version, "运维二组"
+	if err := RoleService.PatchRole(ctx, &request.SysRolePatchReq{Id: role.Id, Version: &staleVersion, Name: &newName}); err == nil {
+		t.Fatal("PatchRole with a stale version succeeded")
+	}
+	if got = getRole(); got.Name != name {
+		t.Fatalf("name = %s after a stale patch, want %s", got.Name, name)
+	}
+}
+
+func TestPatchRoleParentAfterCommit(t *testing.T) {
+	db := setupTestDB(t)
+
+	parent := model.SysRole{Name: "父角色", Code: "parent", Status: 1}
+	db.Create(&parent)
+	child := model.SysRole{Name: "子角色", Code: "child", Status: 1}
+	db.Create(&child)
+	hasParent := func() bool {
+		ok, err := global.Enforcer.HasGroupingPolicy(CasbinService.GetCasbinRoleStr(child.Id), CasbinService.GetCasbinRoleStr(parent.Id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+	patch := &request.SysRolePatchReq{Id: child.Id, ParentId: &parent.Id}
+
+	// 请求级事务回滚时不修改 casbin 的继承关系
+	errLater := errors.New("later step failed")
+	err := common.RunInTx(context.Background(), func(ctx context.Context) error {
+		if err := RoleService.PatchRole(ctx, patch); err != nil {
+			return err
+		}
+		if hasParent() {
+			t.Error("casbin policy changed before the request transaction committed")
+		}
+		return errLater
+	})
+	if !errors.Is(err, errLater) {
+		t.Fatalf("RunInTx err = %v", err)
+	}
+	if hasParent() {
+		t.Fatal("casbin parent set after the request transaction rolled back")
+	}
+
+	err = common.RunInTx(context.Background(), func(ctx context.Context) error {
+		return RoleService.PatchRole(ctx, patch)
+	})
+	if err != nil {
+		t.Fatalf("PatchRole: %v", err)
+	}
+	if !hasParent() {
+		t.Fatal("casbin parent not set after the request transaction committed")
+	}
+}
@@ -0,0 +1,98 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/model"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+)
+
+// RoleAction 角色管理操作
+type RoleAction string
+
+const (
+	RoleActionCreate      RoleAction = "create"       // 新增角色，包含复制和导入
+	RoleActionUpdate      RoleAction = "update"       // 修改角色，包含交换编码和覆盖导入
+	RoleActionDelete      RoleAction = "delete"       // 删除角色
+	RoleActionRestore     RoleAction = "restore"      // 恢复已删除的角色
+	RoleActionAssignMenus RoleAction = "assign-menus" // 分配角色菜单
+	RoleActionAssignUsers RoleAction = "assign-users" // 添加或移除角色下的用户
+)
+
+// AuthorizationPolicy 角色管理的授权策略，角色服务在每次修改角色数据之前调用，返回错误时取消操作。
+// actor 为当前登录用户，没有登录用户的内部调用（如初始化数据）时为 nil；
+// 修改角色时 target 为修改前的角色，修改编码时还会以修改后的角色再校验一次
+type AuthorizationPolicy interface {
+	CanManageRole(ctx context.Context, actor *common.UserClaims, target *model.SysRole, action RoleAction) error
+}
+
+// AllowAllPolicy 默认的授权策略，接口权限由 casbin 控制，不额外限制角色数据
+type AllowAllPolicy struct{}
+
+func (AllowAllPolicy) CanManageRole(context.Context, *common.UserClaims, *model.SysRole, RoleAction) error {
+	return nil
+}
+
+// ReservedCodePolicy 编码以保留前缀开头的角色只有超级管理员可以新增、修改和删除，如 sys: 开头的系统角色
+type ReservedCodePolicy struct {
+	Prefixes []string
+}
+
+// NewReservedCodePolicy 创建保留编码前缀的授权策略，前缀不区分大小写
+func NewReservedCodePolicy(prefixes ...string) *ReservedCodePolicy {
+
+	policy := &ReservedCodePolicy{Prefixes: make([]string, 0, len(prefixes))}
+	for _, prefix := range prefixes {
+		if prefix = normalizeRoleCode(prefix); prefix != "" {
+			policy.Prefixes = append(policy.Prefixes, prefix)
+		}
+	}
+	return policy
+}
+
+func (p *ReservedCodePolicy) CanManageRole(_ context.Context, actor *common.UserClaims, target *model.SysRole, _ RoleAction) error {
+
+	if actor == nil || slices.Contains(actor.RoleCodes, model.SuperAdminRoleCode) {
+		return nil
+	}
+
+	code := normalizeRoleCode(target.Code)
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(code, prefix) {
+			return buserr.ErrPermissionDenied
+		}
+	}
+	return nil
+}
+
+// SetAuthorizationPolicy 设置角色管理的授权策略，传入 nil 时恢复默认策略
+func (roleService *SysRoleService) SetAuthorizationPolicy(policy AuthorizationPolicy) {
+	roleService.policy = policy
+}
+
+// authorize 校验当前登录用户是否可以对角色执行操作
+func (roleService *SysRoleService) authorize(ctx context.Context, target *model.SysRole, action RoleAction) error {
+
+	policy := roleService.policy
+	if policy == nil {
+		policy = AllowAllPolicy{}
+	}
+	return policy.CanManageRole(ctx, common.USER_CTX.GetClaims(ctx), target, action)
+}
+
+// authorizeById 查询角色后校验当前登录用户是否可以对角色执行操作，角色不存在时返回 ErrRoleNotFound
+func (roleService *SysRoleService) authorizeById(ctx context.Context, tx *gorm.DB, roleId uint64, action RoleAction) error {
+
+	var role model.SysRole
+	if err := tx.Model(&model.SysRole{}).Where("id = ?", roleId).Take(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return buserr.ErrRoleNotFound
+		}
+		return err
+	}
+	return roleService.authorize(ctx, &role, action)
+}
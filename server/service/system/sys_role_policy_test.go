@@ -0,0 +1,114 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"testing"
+)
+
+func TestReservedCodePolicy(t *testing.T) {
+	policy := NewReservedCodePolicy("SYS:", " ")
+	user := &common.UserClaims{ID: 2, RoleCodes: []string{"ops"}}
+	superAdmin := &common.UserClaims{ID: 1, RoleCodes: []string{model.SuperAdminRoleCode}}
+
+	tests := []struct {
+		name    string
+		actor   *common.UserClaims
+		code    string
+		wantErr error
+	}{
+		{"user on reserved code", user, "sys:audit", buserr.ErrPermissionDenied},
+		{"user on reserved code in upper case", user, "SYS:Audit", buserr.ErrPermissionDenied},
+		{"user on normal code", user, "ops", nil},
+		{"user on code containing prefix", user, "ops:sys:audit", nil},
+		{"super admin on reserved code", superAdmin, "sys:audit", nil},
+		{"internal call on reserved code", nil, "sys:audit", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.CanManageRole(context.Background(), tt.actor, &model.SysRole{Code: tt.code}, RoleActionUpdate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CanManageRole = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (AllowAllPolicy{}).CanManageRole(context.Background(), user, &model.SysRole{Code: "sys:audit"}, RoleActionDelete); err != nil {
+		t.Fatalf("AllowAllPolicy = %v, want nil", err)
+	}
+}
+
+// recordPolicy 记录授权校验的操作，拒绝指定的操作
+type recordPolicy struct {
+	deny    RoleAction
+	actions []RoleAction
+}
+
+func (p *recordPolicy) CanManageRole(_ context.Context, _ *common.UserClaims, _ *model.SysRole, action RoleAction) error {
+	p.actions = append(p.actions, action)
+	if action == p.deny {
+		return buserr.ErrPermissionDenied
+	}
+	return nil
+}
+
+func TestRoleServiceAuthorizationPolicy(t *testing.T) {
+	db := setupTestDB(t)
+	oldPolicy := RoleService.policy
+	t.Cleanup(func() { RoleService.SetAuthorizationPolicy(oldPolicy) })
+
+	userCtx := context.WithValue(context.Background(), common.ClaimsKey, &common.UserClaims{ID: 2, RoleCodes: []string{"ops"}})
+	addRole := func(ctx context.Context, code string) error {
+		return RoleService.AddRole(&common.Request{Data: &request.SysRoleAddReq{Name: code, Code: code}, Context: ctx})
+	}
+	roleExists := func(code string) bool {
+		var count int64
+		db.Model(&model.SysRole{}).Where("code = ?", code).Count(&count)
+		return count > 0
+	}
+
+	// 默认策略不限制角色数据
+	RoleService.SetAuthorizationPolicy(nil)
+	if err := addRole(userCtx, "sys:default"); err != nil {
+		t.Fatalf("AddRole with default policy: %v", err)
+	}
+
+	// 保留前缀的角色只有超级管理员可以新增，修改编码为保留前缀同样被拒绝
+	RoleService.SetAuthorizationPolicy(NewReservedCodePolicy("sys:"))
+	if err := addRole(userCtx, "sys:audit"); !errors.Is(err, buserr.ErrPermissionDenied) {
+		t.Fatalf("AddRole reserved code err = %v, want ErrPermissionDenied", err)
+	}
+	if roleExists("sys:audit") {
+		t.Fatal("role with reserved code created after the policy denied it")
+	}
+	if err := addRole(userCtx, "ops"); err != nil {
+		t.Fatalf("AddRole normal code: %v", err)
+	}
+	var ops model.SysRole
+	db.Where("code = ?", "ops").First(&ops)
+	editReq := &request.SysRoleEditReq{Id: ops.Id, SysRoleAddReq: request.SysRoleAddReq{Name: ops.Name, Code: "sys:ops"}}
+	if err := RoleService.EditRole(&common.Request{Data: editReq, Context: userCtx}); !errors.Is(err, buserr.ErrPermissionDenied) {
+		t.Fatalf("EditRole to reserved code err = %v, want ErrPermissionDenied", err)
+	}
+	if roleExists("sys:ops") {
+		t.Fatal("role code changed after the policy denied it")
+	}
+	superCtx := context.WithValue(context.Background(), common.ClaimsKey, &common.UserClaims{ID: 1, RoleCodes: []string{model.SuperAdminRoleCode}})
+	if err := addRole(superCtx, "sys:audit"); err != nil {
+		t.Fatalf("AddRole reserved code as super admin: %v", err)
+	}
+
+	// 自定义策略在修改数据之前调用
+	policy := &recordPolicy{deny: RoleActionAssignMenus}
+	RoleService.SetAuthorizationPolicy(policy)
+	if err := RoleService.AssignMenus(userCtx, ops.Id, nil); !errors.Is(err, buserr.ErrPermissionDenied) {
+		t.Fatalf("AssignMenus err = %v, want ErrPermissionDenied", err)
+	}
+	if len(policy.actions) != 1 || policy.actions[0] != RoleActionAssignMenus {
+		t.Fatalf("policy actions = %v, want [%s]", policy.actions, RoleActionAssignMenus)
+	}
+}
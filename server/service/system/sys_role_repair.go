@@ -0,0 +1,127 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/response"
+	mapset "github.com/deckarep/golang-set/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// repairChunkSize 修复关联数据时每批扫描的关联行数
+const repairChunkSize = 1000
+
+// RepairRoleJoins 清理用户角色关联表中角色或用户不存在（包括已删除）的关联行，dryRun=true 时只统计不删除。
+// 按主键分批扫描，每批在单独的事务中删除，避免大表长时间加锁
+func (*SysRoleService) RepairRoleJoins(ctx context.Context, dryRun bool) (*response.RepairReport, error) {
+
+	db := common.DBFromContext(ctx)
+	report := &response.RepairReport{DryRun: dryRun}
+
+	var lastRoleId, lastUserId uint64
+	for {
+		var rows []model.SysUserRole
+		if err := db.Where("sys_role_id > ? OR (sys_role_id = ? AND sys_user_id > ?)", lastRoleId, lastRoleId, lastUserId).
+			Order("sys_role_id").Order("sys_user_id").Limit(repairChunkSize).Find(&rows).Error; err != nil {
+			return report, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		report.Scanned += len(rows)
+		lastRoleId, lastUserId = rows[len(rows)-1].SysRoleId, rows[len(rows)-1].SysUserId
+
+		orphans, err := findOrphanedUserRoles(db, rows, report)
+		if err != nil {
+			return report, err
+		}
+		if !dryRun && len(orphans) > 0 {
+			if err = deleteUserRoles(ctx, db, orphans); err != nil {
+				return report, err
+			}
+			report.Deleted += len(orphans)
+		}
+
+		if len(rows) < repairChunkSize {
+			break
+		}
+	}
+
+	if report.MissingRole+report.MissingUser > 0 {
+		zap.L().Info("用户角色关联数据修复完成", zap.Bool("dryRun", dryRun), zap.Int("scanned", report.Scanned),
+			zap.Int("missingRole", report.MissingRole), zap.Int("missingUser", report.MissingUser), zap.Int("deleted", report.Deleted))
+	}
+	return report, nil
+}
+
+// findOrphanedUserRoles 找出角色或用户不存在的关联行，角色和用户都不存在时计入角色不存在
+func findOrphanedUserRoles(db *gorm.DB, rows []model.SysUserRole, report *response.RepairReport) ([]model.SysUserRole, error) {
+
+	roleIds, userIds := mapset.NewThreadUnsafeSet[uint64](), mapset.NewThreadUnsafeSet[uint64]()
+	for i := range rows {
+		roleIds.Add(rows[i].SysRoleId)
+		userIds.Add(rows[i].SysUserId)
+	}
+
+	var existRoleIds, existUserIds []uint64
+	if err := db.Model(&model.SysRole{}).Where("id IN ?", roleIds.ToSlice()).Pluck("id", &existRoleIds).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&model.SysUser{}).Where("id IN ?", userIds.ToSlice()).Pluck("id", &existUserIds).Error; err != nil {
+		return nil, err
+	}
+	existRoles, existUsers := mapset.NewThreadUnsafeSet(existRoleIds...), mapset.NewThreadUnsafeSet(existUserIds...)
+
+	var orphans []model.SysUserRole
+	for i := range rows {
+		switch {
+		case !existRoles.Contains(rows[i].SysRoleId):
+			report.MissingRole++
+		case !existUsers.Contains(rows[i].SysUserId):
+			report.MissingUser++
+		default:
+			continue
+		}
+		orphans = append(orphans, rows[i])
+	}
+	return orphans, nil
+}
+
+// deleteUserRoles 在一个事务中删除关联行并使相关用户的令牌失效，事务提交后删除对应的casbin用户角色
+func deleteUserRoles(ctx context.Context, db *gorm.DB, userRoles []model.SysUserRole) error {
+
+	pairs := make([][]any, 0, len(userRoles))
+	roleUsers := make(map[uint64][]uint64)
+	userIds := mapset.NewThreadUnsafeSet[uint64]()
+	for i := range userRoles {
+		pairs = append(pairs, []any{userRoles[i].SysRoleId, userRoles[i].SysUserId})
+		roleUsers[userRoles[i].SysRoleId] = append(roleUsers[userRoles[i].SysRoleId], userRoles[i].SysUserId)
+		userIds.Add(userRoles[i].SysUserId)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Where("(sys_role_id, sys_user_id) IN ?", pairs).Delete(&model.SysUserRole{}).Error; err != nil {
+			return err
+		}
+		// 已删除的用户不会被更新，只有角色不存在的用户需要重新获取令牌
+		return incrTokenVersion(tx, userIds.ToSlice())
+	})
+	if err != nil {
+		return err
+	}
+
+	// 令牌版本号已递增，删除casbin用户角色失败时也需要删除缓存
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds.ToSlice()...) })
+
+	// casbin 的策略不随事务回滚，关联行删除成功后再删除对应的用户角色
+	for roleId, roleUserIds := range roleUsers {
+		if err = CasbinService.DeleteUsersForRole(roleId, roleUserIds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/model"
+	"testing"
+)
+
+func TestRepairRoleJoins(t *testing.T) {
+	db := setupTestDB(t)
+
+	role := model.SysRole{Name: "运维", Code: "ops", Status: 1}
+	db.Create(&role)
+	user := model.SysUser{Username: "u1"}
+	db.Create(&user)
+	deletedUser := model.SysUser{Username: "u2"}
+	db.Create(&deletedUser)
+	db.Delete(&deletedUser)
+	db.Create(&[]model.SysUserRole{
+		{SysRoleId: role.Id, SysUserId: user.Id},
+		{SysRoleId: role.Id + 100, SysUserId: user.Id},  // 角色不存在
+		{SysRoleId: role.Id, SysUserId: deletedUser.Id}, // 用户已删除
+	})
+	joinCount := func() int64 {
+		var count int64
+		db.Model(&model.SysUserRole{}).Count(&count)
+		return count
+	}
+
+	// 预览时只统计不删除
+	report, err := RoleService.RepairRoleJoins(context.Background(), true)
+	if err != nil {
+		t.Fatalf("RepairRoleJoins dry run: %v", err)
+	}
+	if report.Scanned != 3 || report.MissingRole != 1 || report.MissingUser != 1 || report.Deleted != 0 {
+		t.Fatalf("dry run report = %+v", report)
+	}
+	if count := joinCount(); count != 3 {
+		t.Fatalf("joins = %d after dry run, want 3", count)
+	}
+
+	report, err = RoleService.RepairRoleJoins(context.Background(), false)
+	if err != nil {
+		t.Fatalf("RepairRoleJoins: %v", err)
+	}
+	if report.Scanned != 3 || report.MissingRole != 1 || report.MissingUser != 1 || report.Deleted != 2 {
+		t.Fatalf("repair report = %+v", report)
+	}
+	var joins []model.SysUserRole
+	db.Find(&joins)
+	if len(joins) != 1 || joins[0].SysRoleId != role.Id || joins[0].SysUserId != user.Id {
+		t.Fatalf("joins after repair = %+v, want only the valid join", joins)
+	}
+}
@@ -1,24 +1,63 @@
 package system
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"gitee.com/nichanghao/gdmin/cache"
 	"gitee.com/nichanghao/gdmin/common"
 	"gitee.com/nichanghao/gdmin/common/buserr"
-	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"gitee.com/nichanghao/gdmin/event"
 	"gitee.com/nichanghao/gdmin/model"
 	"gitee.com/nichanghao/gdmin/web/request"
+	"gitee.com/nichanghao/gdmin/web/response"
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/jinzhu/copier"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+const (
+	// 数据权限过滤的部门字段
+	dataScopeDeptColumn = "dept_id"
+	// 仅本人数据权限过滤的用户字段
+	dataScopeUserColumn = "id"
+)
+
+var (
+	RoleService = new(SysRoleService)
+
+	// 不支持行锁的数据库（sqlite）分配角色菜单时使用的互斥锁
+	assignMenusMu sync.Mutex
+
+	// 角色列表允许排序的字段，防止通过排序参数注入sql
+	roleOrderColumns = map[string]string{
+		"id":        "id",
+		"name":      "name",
+		"code":      "code",
+		"createdAt": "created_at",
+	}
+)
+
+// likeEscaper 转义 LIKE 查询中的通配符，转义字符为 !，各数据库的字符串字面量中均无需再转义
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// SysRoleService 角色服务，通用的增删改查由 BaseService 提供
 type SysRoleService struct {
+	BaseService[model.SysRole]
+	policy AuthorizationPolicy // 角色管理的授权策略，为空时使用默认策略
 }
 
-// PageRoles 分页查询角色列表
-func (*SysRoleService) PageRoles(req *request.SysRolePageReq) (*common.PageResp, error) {
+// PageRoles 分页查询角色列表，查询选项只作用于列表查询
+func (*SysRoleService) PageRoles(ctx context.Context, req *request.SysRolePageReq, opts ...QueryOption) (*common.PageResp, error) {
 
-	tx := global.GormDB.Model(&model.SysRole{}).Limit(req.Limit).Offset(req.Offset)
+	tx := common.DBFromContext(ctx).Model(&model.SysRole{})
 	if req.Name != "" {
 		tx.Where("name LIKE ?", "%"+req.Name+"%")
 	}
@@ -40,8 +79,14 @@ func (*SysRoleService) PageRoles(req *request.SysRolePageReq) (*common.PageResp,
 	}
 
 	// 查询列表
+	orderColumn, ok := roleOrderColumns[req.OrderBy]
+	if !ok {
+		orderColumn = "id"
+	}
+	order := clause.OrderByColumn{Column: clause.Column{Name: orderColumn}, Desc: req.OrderDir != "asc"}
+
 	var roleList []*model.SysRole
-	if err := tx.Find(&roleList).Error; err != nil {
+	if err := applyQueryOptions(tx, opts).Order(order).Limit(req.Limit).Offset(req.Offset).Find(&roleList).Error; err != nil {
 		return res, err
 	}
 	res.Records = roleList
@@ -50,6 +95,39 @@ func (*SysRoleService) PageRoles(req *request.SysRolePageReq) (*common.PageResp,
 
 }
 
+// CursorRoles 按id游标分页查询角色列表，角色数量较少时也可使用 PageRoles
+func (*SysRoleService) CursorRoles(ctx context.Context, req *request.SysRoleCursorReq) (*common.CursorResp, error) {
+
+	afterId, err := req.AfterId()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := common.DBFromContext(ctx).Model(&model.SysRole{}).Where("id > ?", afterId)
+	if req.Name != "" {
+		tx.Where("name LIKE ?", "%"+req.Name+"%")
+	}
+	if req.Code != "" {
+		tx.Where("code LIKE ?", "%"+req.Code+"%")
+	}
+	if req.Status != 0 {
+		tx.Where("status = ?", req.Status)
+	}
+
+	// 多查询一条用于判断是否还有下一页
+	roles := make([]*model.SysRole, 0)
+	if err = tx.Order("id").Limit(req.Limit + 1).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	res := &common.CursorResp{Records: roles}
+	if len(roles) > req.Limit {
+		roles = roles[:req.Limit]
+		res.Records, res.NextCursor = roles, common.EncodeCursor(roles[len(roles)-1].Id)
+	}
+	return res, nil
+}
+
 // AddRole 新增角色
 func (roleService *SysRoleService) AddRole(req *common.Request) error {
 
@@ -57,20 +135,48 @@ func (roleService *SysRoleService) AddRole(req *common.Request) error {
 	if err := copier.Copy(&role, req.Data); err != nil {
 		return err
 	}
+	role.Code = normalizeRoleCode(role.Code)
+	if err := role.Validate(); err != nil {
+		return err
+	}
+	if err := roleService.authorize(req.Context, &role, RoleActionCreate); err != nil {
+		return err
+	}
 
-	return global.GormDB.Model(&model.SysRole{}).Transaction(func(tx *gorm.DB) error {
+	var policies policyChanges
+	err := common.ModelDB(req.Context, &model.SysRole{}).Transaction(func(tx *gorm.DB) error {
 
 		if err := roleService.validateDuplicateRole(tx, &role); err != nil {
 			return err
 		}
 
+		if err := roleService.validateRoleParent(tx, role.Id, role.ParentId); err != nil {
+			return err
+		}
+
 		if err := tx.WithContext(req.Context).Create(&role).Error; err != nil {
 			return err
 		}
 
+		addReq := req.Data.(*request.SysRoleAddReq)
+		if err := roleService.assignRoleDepts(tx, &role, addReq.DeptIds); err != nil {
+			return err
+		}
+		if err := recordRoleHistory(req.Context, tx, model.OperationCreate, role.Id); err != nil {
+			return err
+		}
+
+		policies.add(func() error { return CasbinService.SetRoleParent(role.Id, role.ParentId) })
 		return nil
 	})
+	if err != nil {
+		return translateRoleError(err)
+	}
 
+	policies.applyAfterCommit(req.Context)
+	AuditService.RecordRole(req.Context, model.OperationCreate, nil, &role)
+	publishRoleEvent(req.Context, event.RoleCreated{RoleEvent: newRoleEvent(req.Context, &role)})
+	return nil
 }
 
 // EditRole 编辑角色
@@ -83,12 +189,21 @@ func (roleService *SysRoleService) EditRole(req *common.Request) error {
 	if role.Id == 0 {
 		return buserr.NewNoticeBusErr("角色ID不能为空！")
 	}
+	role.Code = normalizeRoleCode(role.Code)
+	if err := role.Validate(); err != nil {
+		return err
+	}
 
-	return global.GormDB.Model(&model.SysRole{}).Transaction(func(tx *gorm.DB) error {
+	var roleOld, roleNew model.SysRole
+	var userIds []uint64
+	var policies policyChanges
+	err := common.ModelDB(req.Context, &model.SysRole{}).Transaction(func(tx *gorm.DB) error {
 
-		var roleOld = model.SysRole{}
 		if errors.Is(tx.Where("id = ?", role.Id).First(&roleOld).Error, gorm.ErrRecordNotFound) {
-			return buserr.NewNoticeBusErr("该角色不存在！")
+			return buserr.ErrRoleNotFound
+		}
+		if err := roleService.authorize(req.Context, &roleOld, RoleActionUpdate); err != nil {
+			return err
 		}
 
 		if roleOld.Name != role.Name {
@@ -97,111 +212,1228 @@ func (roleService *SysRoleService) EditRole(req *common.Request) error {
 			}
 		}
 
-		if roleOld.Code != role.Code {
+		// 历史数据中的编码可能不是小写，仅大小写不同时视为未修改
+		if !strings.EqualFold(roleOld.Code, role.Code) {
+			if isReservedRole(&roleOld) {
+				return buserr.ErrReservedRole
+			}
+			if err := roleService.authorize(req.Context, &role, RoleActionUpdate); err != nil {
+				return err
+			}
 			if err := roleService.validateDuplicateRoleByCode(tx, role.Code); err != nil {
 				return err
 			}
 		}
 
-		if err := tx.WithContext(req.Context).Where("id = ?", role.Id).Updates(&role).Error; err != nil {
+		changeParent := roleOld.ParentId != role.ParentId
+		if changeParent {
+			if err := roleService.validateRoleParent(tx, role.Id, role.ParentId); err != nil {
+				return err
+			}
+		}
+
+		editReq := req.Data.(*request.SysRoleEditReq)
+		if err := common.UpdateWithVersion(tx.WithContext(req.Context).Where("id = ?", role.Id), &role, editReq.Version); err != nil {
+			return err
+		}
+
+		// 父角色可能被修改为0，Updates 不会更新零值字段，需单独更新
+		if changeParent {
+			if err := tx.Where("id = ?", role.Id).Update("parent_id", role.ParentId).Error; err != nil {
+				return err
+			}
+			policies.add(func() error { return CasbinService.SetRoleParent(role.Id, role.ParentId) })
+		}
+
+		// 未修改数据权限范围时不处理自定义部门
+		if role.DataScope != 0 {
+			if err := roleService.assignRoleDepts(tx, &role, editReq.DeptIds); err != nil {
+				return err
+			}
+		}
+
+		// 查询修改后的数据用于记录操作日志
+		if err := tx.Where("id = ?", role.Id).First(&roleNew).Error; err != nil {
+			return err
+		}
+		if err := recordRoleHistory(req.Context, tx, model.OperationUpdate, role.Id); err != nil {
 			return err
 		}
 
+		// 角色编码或状态变更后，token中携带的角色编码已过期，拥有该角色的用户需重新获取token
+		if roleOld.Code != roleNew.Code || roleOld.Status != roleNew.Status {
+			var err error
+			if userIds, err = roleUserIds(tx, role.Id); err != nil {
+				return err
+			}
+			return incrTokenVersion(tx, userIds)
+		}
 		return nil
 	})
+	if err != nil {
+		return translateRoleError(err)
+	}
+
+	policies.applyAfterCommit(req.Context)
+	common.AfterCommit(req.Context, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	AuditService.RecordRole(req.Context, model.OperationUpdate, &roleOld, &roleNew)
+	publishRoleEvent(req.Context, event.RoleUpdated{RoleEvent: newRoleEvent(req.Context, &roleNew)})
+	return nil
+}
+
+// DeleteRole 删除角色，角色已分配给用户时需强制删除，强制删除会同时清除用户与角色的关联。
+// 预览时在事务中执行删除并统计影响的数据，然后回滚事务，不修改任何数据
+func (roleService *SysRoleService) DeleteRole(req *common.Request) (*response.SysRoleDeleteResp, error) {
+
+	deleteReq := req.Data.(*request.SysRoleDeleteReq)
+
+	res := &response.SysRoleDeleteResp{DryRun: deleteReq.DryRun, Roles: make([]response.RoleDeleteSummary, 0), Failed: make([]response.RoleDeleteFailure, 0)}
+	var deletion *roleDeletion
+	err := common.ModelDB(req.Context, &model.SysRole{}).Transaction(func(tx *gorm.DB) (err error) {
+		if deletion, err = roleService.deleteRole(req.Context, tx, deleteReq.Id, deleteReq.Force, deleteReq.DryRun); err != nil {
+			return err
+		}
+		return rollbackIfDryRun(deleteReq.DryRun)
+	})
+	if err = ignoreDryRunRollback(err); err != nil {
+		return nil, err
+	}
+
+	if deletion.blocked != nil {
+		res.AddFailed(deletion.summary(), buserr.Localize(deletion.blocked, i18n.LocaleFrom(req.Context)))
+		return res, nil
+	}
+	res.Add(deletion.summary())
+	if deleteReq.DryRun {
+		return res, nil
+	}
+
+	deletion.policies.applyAfterCommit(req.Context)
+	common.AfterCommit(req.Context, func() { cache.SysUserCache.DelSysUserTokenVersion(deletion.userIds...) })
+	AuditService.RecordRole(req.Context, model.OperationDelete, deletion.role, nil)
+	publishRoleEvent(req.Context, event.RoleDeleted{RoleEvent: newRoleEvent(req.Context, deletion.role)})
+	return res, nil
+}
+
+// DeleteRoles 在同一个事务中批量删除角色，返回删除结果及删除失败的角色和原因；
+// 非强制删除时只要有角色已分配给用户，则整批不删除。预览时事务总是回滚，不修改任何数据
+func (roleService *SysRoleService) DeleteRoles(ctx context.Context, ids []uint64, force, dryRun bool) (*response.SysRoleDeleteResp, map[uint64]error, error) {
+
+	res := &response.SysRoleDeleteResp{DryRun: dryRun, Roles: make([]response.RoleDeleteSummary, 0), Failed: make([]response.RoleDeleteFailure, 0)}
+	errs := make(map[uint64]error)
+	var deletions []*roleDeletion
+	err := common.ModelDB(ctx, &model.SysRole{}).Transaction(func(tx *gorm.DB) error {
+
+		// 非强制删除时先检查所有角色，避免删除部分角色后再回滚
+		if !force {
+			var counts []struct {
+				SysRoleId uint64
+				Count     int64
+			}
+			if err := tx.Model(&model.SysUserRole{}).Select("sys_role_id, COUNT(*) AS count").
+				Where("sys_role_id IN ?", ids).Group("sys_role_id").Find(&counts).Error; err != nil {
+				return err
+			}
+			for _, count := range counts {
+				errs[count.SysRoleId] = buserr.NewRoleInUseErr(count.Count)
+			}
+			if len(errs) > 0 {
+				return nil
+			}
+		}
+
+		deletedIds := mapset.NewThreadUnsafeSet[uint64]()
+		for _, id := range ids {
+			if !deletedIds.Add(id) {
+				continue
+			}
+			deletion, err := roleService.deleteRole(ctx, tx, id, force, dryRun)
+			var busErr *buserr.BusinessError
+			if errors.As(err, &busErr) {
+				errs[id] = err
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			deletions = append(deletions, deletion)
+		}
+		return rollbackIfDryRun(dryRun)
+	})
+	if err = ignoreDryRunRollback(err); err != nil {
+		return nil, nil, err
+	}
+
+	var userIds []uint64
+	for _, deletion := range deletions {
+		res.Add(deletion.summary())
+		userIds = append(userIds, deletion.userIds...)
+	}
+	if dryRun {
+		return res, errs, nil
+	}
+
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	for _, deletion := range deletions {
+		deletion.policies.applyAfterCommit(ctx)
+		AuditService.RecordRole(ctx, model.OperationDelete, deletion.role, nil)
+		publishRoleEvent(ctx, event.RoleDeleted{RoleEvent: newRoleEvent(ctx, deletion.role)})
+	}
+	return res, errs, nil
+}
+
+// roleDeletion 删除角色的结果
+type roleDeletion struct {
+	role      *model.SysRole
+	userIds   []uint64      // 强制删除时解除关联的用户，已签发的token失效
+	menuCount int64         // 移除权限的菜单数量
+	policies  policyChanges // 事务提交后执行的casbin策略修改
+	blocked   error         // 预览非强制删除时删除会失败的原因
+}
+
+func (d *roleDeletion) summary() response.RoleDeleteSummary {
+	return response.RoleDeleteSummary{
+		Id: d.role.Id, Name: d.role.Name, Code: d.role.Code, UserBindings: len(d.userIds), MenuBindings: d.menuCount,
+	}
+}
+
+// errDryRun 预览删除时用于回滚事务
+var errDryRun = errors.New("dry run")
+
+func rollbackIfDryRun(dryRun bool) error {
+	if dryRun {
+		return errDryRun
+	}
+	return nil
+}
+
+func ignoreDryRunRollback(err error) error {
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
+
+// deleteRole 在事务中删除角色，返回被删除的角色及解除的关联。
+// casbin 策略不在数据库事务中，需要的策略修改记录在返回结果中，由调用方在事务提交后执行
+func (roleService *SysRoleService) deleteRole(ctx context.Context, tx *gorm.DB, roleId uint64, force, dryRun bool) (*roleDeletion, error) {
+
+	var role model.SysRole
+	if errors.Is(tx.Where("id = ?", roleId).First(&role).Error, gorm.ErrRecordNotFound) {
+		return nil, buserr.ErrRoleNotFound
+	}
+	if isReservedRole(&role) {
+		return nil, buserr.ErrReservedRole
+	}
+	if err := roleService.authorize(ctx, &role, RoleActionDelete); err != nil {
+		return nil, err
+	}
+
+	deletion := &roleDeletion{role: &role}
+	association := tx.Model(&role).Association("Users")
+	if association.Error != nil {
+		return nil, association.Error
+	}
+	if userCount := association.Count(); userCount > 0 {
+		if !force && !dryRun {
+			return nil, buserr.NewRoleInUseErr(userCount)
+		}
+		// 预览时继续统计删除的影响，同时返回非强制删除失败的原因
+		if !force {
+			deletion.blocked = buserr.NewRoleInUseErr(userCount)
+		}
+
+		// 强制删除时，拥有该角色的用户已签发的token失效
+		var err error
+		if deletion.userIds, err = roleUserIds(tx, role.Id); err != nil {
+			return nil, err
+		}
+		if err = incrTokenVersion(tx, deletion.userIds); err != nil {
+			return nil, err
+		}
+		if err = association.Clear(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 角色菜单关联保留用于恢复角色，只移除casbin中的权限策略
+	if err := tx.Model(&model.SysRoleMenu{}).Where("sys_role_id = ?", roleId).Count(&deletion.menuCount).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.WithContext(ctx).Delete(&model.SysRole{}, roleId).Error; err != nil {
+		return nil, err
+	}
+	if err := recordRoleHistory(ctx, tx, model.OperationDelete, roleId); err != nil {
+		return nil, err
+	}
+
+	// 子角色改为继承被删除角色的父角色
+	childIds, err := roleService.reassignChildRoles(tx, role.Id, role.ParentId)
+	if err == nil {
+		err = recordRoleHistory(ctx, tx, model.OperationUpdate, childIds...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 删除casbin中角色的权限策略、继承关系及用户与角色的关联
+	deletion.policies.add(func() error { return CasbinService.DeleteRole(roleId) })
+	for _, childId := range childIds {
+		deletion.policies.add(func() error { return CasbinService.SetRoleParent(childId, role.ParentId) })
+	}
+	return deletion, nil
+}
+
+// PageDeletedRoles 分页查询已删除的角色（回收站）
+func (*SysRoleService) PageDeletedRoles(ctx context.Context, req *common.PageReq) (*common.PageResp, error) {
+
+	tx := common.DBFromContext(ctx).Unscoped().Model(&model.SysRole{}).Where("deleted_at IS NOT NULL")
+
+	res := &common.PageResp{Current: req.Current, Size: req.Size, Records: make([]any, 0)}
+
+	// 查询数量
+	if err := tx.Count(&res.Total).Error; err != nil {
+		return res, err
+	}
+	if res.Total == 0 {
+		return res, nil
+	}
+
+	// 查询列表
+	var roleList []*model.SysRole
+	if err := tx.Order("deleted_at DESC").Limit(req.Limit).Offset(req.Offset).Find(&roleList).Error; err != nil {
+		return res, err
+	}
+	res.Records = roleList
+
+	return res, nil
+}
+
+// roleRestoreAudit 恢复角色的操作日志数据，删除状态不在角色的 json 字段中，需单独记录
+type roleRestoreAudit struct {
+	*model.SysRole
+	Deleted bool `json:"deleted"`
 }
 
-// DeleteRole 删除角色
-func (roleService *SysRoleService) DeleteRole(req *common.Request) error {
+// RestoreRole 恢复已删除的角色
+func (roleService *SysRoleService) RestoreRole(req *common.Request) error {
 
 	roleId := req.Data.(*request.QueryIdReq).Id
 
-	return global.GormDB.Model(&model.SysRole{}).Transaction(func(tx *gorm.DB) error {
-		var role = model.SysRole{}
+	var role model.SysRole
+	var policies policyChanges
+	err := common.DBFromContext(req.Context).Transaction(func(tx *gorm.DB) error {
+
+		if errors.Is(tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", roleId).First(&role).Error, gorm.ErrRecordNotFound) {
+			return buserr.ErrRoleNotFound
+		}
+		roleOld := role
+		if err := roleService.authorize(req.Context, &role, RoleActionRestore); err != nil {
+			return err
+		}
+
+		// 角色删除后可能已有同名或同编码的角色
+		if err := roleService.validateDuplicateRole(tx.Model(&model.SysRole{}), &role); err != nil {
+			return err
+		}
+
+		if err := tx.WithContext(req.Context).Unscoped().Model(&role).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
 
-		if errors.Is(tx.Where("id = ?", roleId).Preload("Users").First(&role).Error, gorm.ErrRecordNotFound) {
-			return buserr.NewNoticeBusErr("该角色不存在！")
+		// 父角色已不存在时恢复为无父角色
+		if role.ParentId != 0 {
+			var count int64
+			if err := tx.Model(&model.SysRole{}).Where("id = ?", role.ParentId).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				role.ParentId = 0
+				if err := tx.Model(&role).Update("parent_id", 0).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if err := recordRoleHistory(req.Context, tx, model.OperationUpdate, roleId); err != nil {
+			return err
+		}
+		var roleNew model.SysRole
+		if err := tx.Where("id = ?", roleId).First(&roleNew).Error; err != nil {
+			return err
 		}
-		if len(role.Users) > 0 {
-			return buserr.NewNoticeBusErr("该角色已分配给用户，不能删除！")
+		if err := AuditService.RecordTx(req.Context, tx, model.OperationUpdate, AuditResourceRole,
+			roleRestoreAudit{SysRole: &roleOld, Deleted: true}, roleRestoreAudit{SysRole: &roleNew}); err != nil {
+			return err
 		}
+		policies.add(func() error { return CasbinService.SetRoleParent(role.Id, role.ParentId) })
 
-		return tx.WithContext(req.Context).Delete(&model.SysRole{}, roleId).Error
+		// 根据角色绑定的菜单恢复casbin权限策略
+		var menus []model.SysMenu
+		menuIds := tx.Model(&model.SysRoleMenu{}).Select("sys_menu_id").Where("sys_role_id = ?", roleId)
+		if err := tx.Model(&model.SysMenu{}).Select("id, permission").Where("id IN (?)", menuIds).Find(&menus).Error; err != nil {
+			return err
+		}
+		rules := make([][]string, 0, len(menus))
+		for i := range menus {
+			rules = append(rules, []string{menus[i].Permission, strconv.FormatUint(menus[i].Id, 10)})
+		}
+		policies.add(func() error { return CasbinService.UpdateRolePolicies(roleId, rules) })
+		return nil
 	})
+	if err != nil {
+		return translateRoleError(err)
+	}
 
+	policies.applyAfterCommit(req.Context)
+
+	publishRoleEvent(req.Context, event.RoleRestored{RoleEvent: newRoleEvent(req.Context, &role)})
+	return nil
 }
 
 // AssignRoleMenus 分配角色菜单
 func (roleService *SysRoleService) AssignRoleMenus(_req *common.Request) error {
 
 	req := _req.Data.(*request.SysAssignRoleMenuReq)
+	return roleService.AssignMenus(_req.Context, req.RoleId, req.MenuIds)
+}
 
-	// 1. 获取角色拥有的菜单
-	menuIds, err := CasbinService.GetPermissionMenuIdsByRoleId(req.RoleId)
-	if err != nil {
-		return err
+// AssignMenus 分配角色菜单，在同一事务中删除角色已绑定的菜单并写入新的菜单集合。
+// 事务开始时通过 SELECT ... FOR UPDATE 锁定角色行，同一角色的并发分配会排队执行，避免关联表数据互相覆盖；
+// sqlite 不支持行锁，改为使用进程内的互斥锁串行执行
+func (roleService *SysRoleService) AssignMenus(ctx context.Context, roleId uint64, menuIds []uint64) error {
+
+	menuIds = mapset.NewSet(menuIds...).ToSlice()
+
+	db := common.DBFromContext(ctx)
+	if !supportsRowLocking(db) {
+		assignMenusMu.Lock()
+		defer assignMenusMu.Unlock()
 	}
 
-	// 2. 计算需要新增和删除的菜单
-	existMenus := mapset.NewSet(menuIds...)
-	needHandleMenus := mapset.NewSet(req.MenuIds...)
+	var policies policyChanges
+	err := db.Transaction(func(tx *gorm.DB) error {
 
-	needAddMenus := needHandleMenus.Difference(existMenus)
-	needDelMenus := existMenus.Difference(needHandleMenus)
+		if err := lockRole(tx, roleId); err != nil {
+			return err
+		}
+		if err := roleService.authorizeById(ctx, tx, roleId, RoleActionAssignMenus); err != nil {
+			return err
+		}
 
-	// 3. 处理数据
-	var addMenus []model.SysMenu
-	err = global.GormDB.Model(&model.SysMenu{}).Select("id, permission").Where("id IN ?", needAddMenus.ToSlice()).Find(&addMenus).Error
+		// 1. 校验菜单是否存在
+		var menus []model.SysMenu
+		if len(menuIds) > 0 {
+			if err := tx.Model(&model.SysMenu{}).Select("id, permission").Where("id IN ?", menuIds).Find(&menus).Error; err != nil {
+				return err
+			}
+		}
+		if len(menus) != len(menuIds) {
+			existMenus := mapset.NewThreadUnsafeSet[uint64]()
+			for i := range menus {
+				existMenus.Add(menus[i].Id)
+			}
+			notExistMenus := mapset.NewThreadUnsafeSet(menuIds...).Difference(existMenus)
+			return buserr.NewNoticeBusErr(fmt.Sprintf("菜单不存在：%v", notExistMenus.ToSlice()))
+		}
+
+		// 2. 重新绑定角色菜单并同步casbin权限
+		if err := roleService.bindRoleMenus(tx, roleId, menus, &policies); err != nil {
+			return err
+		}
+		return recordRoleHistory(ctx, tx, model.OperationUpdate, roleId)
+	})
 	if err != nil {
 		return err
 	}
-	err = CasbinService.AddPermissionByRoleAndMenus(req.RoleId, addMenus)
-	err = CasbinService.DeletePermissionByRoleAndMenus(req.RoleId, needDelMenus.ToSlice())
-	return err
-}
-
-// AllSimpleRoles 获取角色列表（用户管理页面分配用户角色时展示使用）
-func (*SysRoleService) AllSimpleRoles() (roles []*model.SysRole, err error) {
 
-	err = global.GormDB.Model(&model.SysRole{}).Select("id, name, code").Where("status = ?", 1).Find(&roles).Error
-	return
+	policies.applyAfterCommit(ctx)
+	return nil
 }
 
-// 校验角色名称和编码是否重复
-func (roleService *SysRoleService) validateDuplicateRole(tx *gorm.DB, role *model.SysRole) error {
-	if err := roleService.validateDuplicateRoleByName(tx, role.Name); err != nil {
-		return err
+// lockRole 锁定角色行直至事务结束，角色不存在时返回 ErrRoleNotFound
+func lockRole(tx *gorm.DB, roleId uint64) error {
+
+	q := tx.Model(&model.SysRole{}).Select("id").Where("id = ?", roleId)
+	if supportsRowLocking(tx) {
+		q = q.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
 	}
 
-	if err := roleService.validateDuplicateRoleByCode(tx, role.Code); err != nil {
+	var role model.SysRole
+	if err := q.Take(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return buserr.ErrRoleNotFound
+		}
 		return err
 	}
-
 	return nil
 }
 
-func (*SysRoleService) validateDuplicateRoleByName(tx *gorm.DB, name string) error {
-	var count int64
+// supportsRowLocking 数据库是否支持 SELECT ... FOR UPDATE
+func supportsRowLocking(db *gorm.DB) bool {
+	return db.Dialector.Name() != "sqlite"
+}
 
-	if err := tx.Where("name = ?", name).Limit(1).Count(&count).Error; err != nil {
+// bindRoleMenus 删除角色已绑定的菜单并写入新的菜单集合，casbin权限的同步记录在 policies 中，menus 需包含 id 和 permission
+func (roleService *SysRoleService) bindRoleMenus(tx *gorm.DB, roleId uint64, menus []model.SysMenu, policies *policyChanges) error {
+
+	if err := tx.Model(&model.SysRoleMenu{}).Where("sys_role_id = ?", roleId).Delete(&model.SysRoleMenu{}).Error; err != nil {
 		return err
 	}
-	if count > 0 {
-		return buserr.NewNoticeBusErr("角色名称已存在！")
+	// 菜单变更时刷新角色的修改时间，使角色的 ETag 随之变化
+	if err := tx.Model(&model.SysRole{}).Where("id = ?", roleId).UpdateColumn("updated_at", time.Now()).Error; err != nil {
+		return err
+	}
+	if len(menus) > 0 {
+		roleMenus := make([]model.SysRoleMenu, 0, len(menus))
+		for i := range menus {
+			roleMenus = append(roleMenus, model.SysRoleMenu{SysRoleId: roleId, SysMenuId: menus[i].Id})
+		}
+		if err := tx.Model(&model.SysRoleMenu{}).Create(&roleMenus).Error; err != nil {
+			return err
+		}
 	}
 
+	policies.add(func() error { return roleService.syncMenuPolicies(roleId, menus) })
 	return nil
 }
 
-func (*SysRoleService) validateDuplicateRoleByCode(tx *gorm.DB, code string) error {
-	var count int64
+// GetMenuIdsByRole 获取角色绑定的菜单id
+func (*SysRoleService) GetMenuIdsByRole(ctx context.Context, roleId uint64) ([]uint64, error) {
 
-	if err := tx.Where("code = ?", code).Limit(1).Count(&count).Error; err != nil {
+	menuIds := make([]uint64, 0)
+	err := common.DBFromContext(ctx).Model(&model.SysRoleMenu{}).Where("sys_role_id = ?", roleId).Pluck("sys_menu_id", &menuIds).Error
+	return menuIds, err
+}
+
+// GetEffectiveMenuIds 获取角色的有效菜单id，包含沿父角色链继承的菜单
+func (*SysRoleService) GetEffectiveMenuIds(ctx context.Context, roleId uint64) ([]uint64, error) {
+
+	db := common.DBFromContext(ctx)
+	roleIds, err := roleAncestorIds(db, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	menuIds := make([]uint64, 0)
+	err = db.Model(&model.SysRoleMenu{}).Distinct("sys_menu_id").
+		Where("sys_role_id IN ?", roleIds).Pluck("sys_menu_id", &menuIds).Error
+	return menuIds, err
+}
+
+// GetUserPermissions 获取用户拥有的路由名称和权限标识，由用户已启用角色的有效菜单（包含继承的菜单）计算，
+// 只包含已启用的菜单，结果已去重并排序
+func (roleService *SysRoleService) GetUserPermissions(ctx context.Context, userId uint64) (*response.SysUserPermissionsResp, error) {
+
+	res := &response.SysUserPermissionsResp{Routes: make([]string, 0), Permissions: make([]string, 0)}
+
+	db := common.DBFromContext(ctx)
+	var roleIds []uint64
+	userRoleIds := db.Model(&model.SysUserRole{}).Select("sys_role_id").Where("sys_user_id = ?", userId)
+	if err := db.Model(&model.SysRole{}).Where("id IN (?) AND status = ?", userRoleIds, 1).Pluck("id", &roleIds).Error; err != nil {
+		return nil, err
+	}
+
+	menuIds := mapset.NewThreadUnsafeSet[uint64]()
+	for _, roleId := range roleIds {
+		ids, err := roleService.GetEffectiveMenuIds(ctx, roleId)
+		if err != nil {
+			return nil, err
+		}
+		menuIds.Append(ids...)
+	}
+	if menuIds.Cardinality() == 0 {
+		return res, nil
+	}
+
+	var menus []model.SysMenu
+	if err := db.Model(&model.SysMenu{}).Select("id, type, route_name, permission").
+		Where("status = 1 AND id IN ?", menuIds.ToSlice()).Find(&menus).Error; err != nil {
+		return nil, err
+	}
+
+	routes, permissions := mapset.NewThreadUnsafeSet[string](), mapset.NewThreadUnsafeSet[string]()
+	for i := range menus {
+		if menus[i].Type != 3 && menus[i].RouteName != "" {
+			routes.Add(menus[i].RouteName)
+		}
+		if menus[i].Permission != "" {
+			permissions.Add(menus[i].Permission)
+		}
+	}
+	res.Routes, res.Permissions = routes.ToSlice(), permissions.ToSlice()
+	sort.Strings(res.Routes)
+	sort.Strings(res.Permissions)
+	return res, nil
+}
+
+// GetRole 获取角色详情，包含自定义数据权限的部门和角色直接绑定的菜单id，不加载角色的用户
+func (roleService *SysRoleService) GetRole(ctx context.Context, roleId uint64) (*response.SysRoleDetailResp, error) {
+
+	db := common.DBFromContext(ctx)
+
+	var role model.SysRole
+	if err := db.Preload("Depts").Take(&role, roleId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, buserr.ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	menuIds, err := roleService.GetMenuIdsByRole(ctx, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.SysRoleDetailResp{SysRole: &role, MenuIds: menuIds}, nil
+}
+
+// GetRoleListStamp 获取角色列表的变更标识：角色数量和最近的修改时间，用于生成列表的 ETag
+func (*SysRoleService) GetRoleListStamp(ctx context.Context) (count int64, updatedAt time.Time, err error) {
+
+	db := common.DBFromContext(ctx)
+	if err = db.Model(&model.SysRole{}).Count(&count).Error; err != nil || count == 0 {
+		return
+	}
+
+	var role model.SysRole
+	err = db.Model(&model.SysRole{}).Select("updated_at").Order("updated_at DESC").Take(&role).Error
+	return count, role.UpdatedAt, err
+}
+
+// GetRolesByUser 获取用户拥有的角色，不加载角色的用户，没有角色时返回空数组
+func (*SysRoleService) GetRolesByUser(ctx context.Context, userId uint64) ([]model.SysRole, error) {
+
+	db := common.DBFromContext(ctx)
+	roleIds := db.Model(&model.SysUserRole{}).Select("sys_role_id").Where("sys_user_id = ?", userId)
+
+	roles := make([]model.SysRole, 0)
+	err := db.Model(&model.SysRole{}).Where("id IN (?)", roleIds).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// GetRolesByMenu 获取直接绑定了菜单的角色（不包含通过继承获得菜单的子角色），没有角色时返回空数组
+func (*SysRoleService) GetRolesByMenu(ctx context.Context, menuId uint64) ([]model.SysRole, error) {
+
+	db := common.DBFromContext(ctx)
+	roleIds := db.Model(&model.SysRoleMenu{}).Select("sys_role_id").Where("sys_menu_id = ?", menuId)
+
+	roles := make([]model.SysRole, 0)
+	err := db.Model(&model.SysRole{}).Where("id IN (?)", roleIds).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// SearchRolesByPermissionCode 获取直接绑定了指定权限标识菜单的角色（不包含通过继承获得菜单的子角色），
+// prefix=true 时按前缀匹配，权限标识末尾的 * 可省略；没有角色时返回空数组
+func (*SysRoleService) SearchRolesByPermissionCode(ctx context.Context, code string, prefix bool) ([]model.SysRole, error) {
+
+	code = strings.TrimSpace(code)
+	if prefix {
+		code = strings.TrimSuffix(code, "*")
+	}
+	if code == "" {
+		return nil, buserr.NewNoticeBusErr("权限标识不能为空！")
+	}
+
+	db := common.DBFromContext(ctx)
+	menuIds := db.Model(&model.SysMenu{}).Select("id")
+	if prefix {
+		// 转义通配符，权限标识中的 _ 和 % 按普通字符匹配
+		menuIds.Where("permission LIKE ? ESCAPE '!'", likeEscaper.Replace(code)+"%")
+	} else {
+		menuIds.Where("permission = ?", code)
+	}
+	roleIds := db.Model(&model.SysRoleMenu{}).Select("sys_role_id").Where("sys_menu_id IN (?)", menuIds)
+
+	// 通过子查询过滤，同一角色绑定多个匹配的菜单时只返回一次
+	roles := make([]model.SysRole, 0)
+	err := db.Model(&model.SysRole{}).Where("id IN (?)", roleIds).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// GetRoleByCode 根据编码查询角色，编码不区分大小写
+func (*SysRoleService) GetRoleByCode(ctx context.Context, code string) (*model.SysRole, error) {
+
+	var role model.SysRole
+	err := common.DBFromContext(ctx).Model(&model.SysRole{}).Where("LOWER(code) = ?", normalizeRoleCode(code)).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, buserr.ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetRoleTree 获取角色树
+func (*SysRoleService) GetRoleTree(ctx context.Context) (res []*response.RoleNode, err error) {
+
+	var nodes []*response.RoleNode
+	if err = common.DBFromContext(ctx).Model(&model.SysRole{}).Select("id, name, code, status, parent_id").Find(&nodes).Error; err != nil {
+		return
+	}
+
+	nodeMap := make(map[uint64]*response.RoleNode, len(nodes))
+	for i := range nodes {
+		nodes[i].Children = make([]*response.RoleNode, 0)
+		nodeMap[nodes[i].Id] = nodes[i]
+	}
+
+	res = make([]*response.RoleNode, 0)
+	for _, node := range nodes {
+		// 父角色不存在时作为根节点展示
+		if parent, ok := nodeMap[node.ParentId]; ok && node.ParentId != node.Id {
+			parent.Children = append(parent.Children, node)
+		} else {
+			res = append(res, node)
+		}
+	}
+	return
+}
+
+// validateRoleParent 校验父角色是否存在，且设置后不会形成循环继承
+func (*SysRoleService) validateRoleParent(tx *gorm.DB, roleId, parentId uint64) error {
+	if parentId == 0 {
+		return nil
+	}
+	if parentId == roleId {
+		return buserr.ErrRoleCycle
+	}
+
+	ancestorIds, err := roleAncestorIds(tx, parentId)
+	if errors.Is(err, buserr.ErrRoleNotFound) {
+		return buserr.NewNoticeBusErr("父角色不存在！")
+	}
+	if err != nil {
+		return err
+	}
+	if roleId != 0 && mapset.NewThreadUnsafeSet(ancestorIds...).Contains(roleId) {
+		return buserr.ErrRoleCycle
+	}
+
+	return nil
+}
+
+// reassignChildRoles 将角色的子角色重新挂载到新的父角色下，返回子角色id，casbin中的继承关系需调用方同步
+func (*SysRoleService) reassignChildRoles(tx *gorm.DB, roleId, newParentId uint64) ([]uint64, error) {
+
+	var childIds []uint64
+	if err := tx.Model(&model.SysRole{}).Where("parent_id = ?", roleId).Pluck("id", &childIds).Error; err != nil {
+		return nil, err
+	}
+	if len(childIds) == 0 {
+		return nil, nil
+	}
+
+	if err := tx.Model(&model.SysRole{}).Where("id IN ?", childIds).Update("parent_id", newParentId).Error; err != nil {
+		return nil, err
+	}
+	return childIds, nil
+}
+
+// roleUserIds 获取拥有角色的用户id
+func roleUserIds(tx *gorm.DB, roleId uint64) ([]uint64, error) {
+
+	userIds := make([]uint64, 0)
+	err := tx.Model(&model.SysUserRole{}).Where("sys_role_id = ?", roleId).Pluck("sys_user_id", &userIds).Error
+	return userIds, err
+}
+
+// roleAncestorIds 获取角色及其所有祖先角色的id，父角色链存在循环时返回 ErrRoleCycle
+func roleAncestorIds(db *gorm.DB, roleId uint64) ([]uint64, error) {
+
+	visited := mapset.NewThreadUnsafeSet[uint64]()
+	roleIds := make([]uint64, 0)
+	for id := roleId; id != 0; {
+		if !visited.Add(id) {
+			return nil, buserr.ErrRoleCycle
+		}
+
+		var role model.SysRole
+		err := db.Session(&gorm.Session{NewDB: true}).Model(&model.SysRole{}).Select("id, parent_id").
+			Where("id = ?", id).First(&role).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 起始角色不存在时报错，祖先角色不存在时视为继承链结束
+			if id == roleId {
+				return nil, buserr.ErrRoleNotFound
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		roleIds = append(roleIds, id)
+		id = role.ParentId
+	}
+	return roleIds, nil
+}
+
+// syncMenuPolicies 将角色的菜单同步至casbin权限策略
+func (*SysRoleService) syncMenuPolicies(roleId uint64, menus []model.SysMenu) error {
+
+	// 1. 获取角色在casbin中拥有的菜单
+	menuIds, err := CasbinService.GetPermissionMenuIdsByRoleId(roleId)
+	if err != nil {
+		return err
+	}
+
+	// 2. 计算需要新增和删除的菜单
+	menuMap := make(map[uint64]model.SysMenu, len(menus))
+	for i := range menus {
+		menuMap[menus[i].Id] = menus[i]
+	}
+	existMenus := mapset.NewThreadUnsafeSet(menuIds...)
+	needHandleMenus := mapset.NewThreadUnsafeSetFromMapKeys(menuMap)
+
+	needDelMenus := existMenus.Difference(needHandleMenus)
+	var addMenus []model.SysMenu
+	for _, menuId := range needHandleMenus.Difference(existMenus).ToSlice() {
+		addMenus = append(addMenus, menuMap[menuId])
+	}
+
+	// 3. 处理数据
+	if err = CasbinService.AddPermissionByRoleAndMenus(roleId, addMenus); err != nil {
+		return err
+	}
+	return CasbinService.DeletePermissionByRoleAndMenus(roleId, needDelMenus.ToSlice())
+}
+
+// PageRoleUsers 分页查询角色下的用户，用户量大时深分页较慢，建议使用 CursorRoleUsers
+func (roleService *SysRoleService) PageRoleUsers(ctx context.Context, req *request.SysRoleUserPageReq) (*common.PageResp, error) {
+
+	res := &common.PageResp{Current: req.Current, Size: req.Size, Records: make([]any, 0)}
+
+	users, total, err := roleService.GetRoleUsers(ctx, req.RoleId, req.Current, req.Size)
+	if err != nil {
+		return res, err
+	}
+	res.Total = total
+	if len(users) > 0 {
+		res.Records = users
+	}
+
+	return res, nil
+}
+
+// CursorRoleUsers 按id游标分页查询角色下的用户，不统计总数，适用于用户量大的角色
+func (*SysRoleService) CursorRoleUsers(ctx context.Context, req *request.SysRoleUserCursorReq) (*common.CursorResp, error) {
+
+	afterId, err := req.AfterId()
+	if err != nil {
+		return nil, err
+	}
+
+	db := common.DBFromContext(ctx)
+	userIds := db.Model(&model.SysUserRole{}).Select("sys_user_id").Where("sys_role_id = ?", req.RoleId)
+
+	// 多查询一条用于判断是否还有下一页
+	users := make([]model.SysUser, 0)
+	if err = db.Model(&model.SysUser{}).Where("id IN (?) AND id > ?", userIds, afterId).
+		Order("id").Limit(req.Limit + 1).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	res := &common.CursorResp{Records: users}
+	if len(users) > req.Limit {
+		users = users[:req.Limit]
+		res.Records, res.NextCursor = users, common.EncodeCursor(users[len(users)-1].Id)
+	}
+	return res, nil
+}
+
+// GetRoleUsers 分页查询角色下的用户，通过关联表过滤用户，不加载角色的全部用户
+func (*SysRoleService) GetRoleUsers(ctx context.Context, roleId uint64, page, size int) ([]model.SysUser, int64, error) {
+
+	db := common.DBFromContext(ctx)
+	userIds := db.Model(&model.SysUserRole{}).Select("sys_user_id").Where("sys_role_id = ?", roleId)
+	tx := db.Model(&model.SysUser{}).Where("id IN (?)", userIds)
+
+	// 查询数量
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	// 查询列表
+	var users []model.SysUser
+	if err := tx.Order("id").Limit(size).Offset((page - 1) * size).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// PageRolesWithUserCount 分页查询角色列表及每个角色关联的用户数量
+func (roleService *SysRoleService) PageRolesWithUserCount(ctx context.Context, req *common.PageReq) (*common.PageResp, error) {
+
+	res := &common.PageResp{Current: req.Current, Size: req.Size, Records: make([]any, 0)}
+
+	roles, total, err := roleService.ListRolesWithUserCount(ctx, req.Current, req.Size)
+	if err != nil {
+		return res, err
+	}
+	res.Total = total
+	if len(roles) > 0 {
+		res.Records = roles
+	}
+
+	return res, nil
+}
+
+// ListRolesWithUserCount 分页查询角色及其关联的用户数量，通过关联表分组统计，不加载角色的用户
+func (*SysRoleService) ListRolesWithUserCount(ctx context.Context, page, size int) ([]response.RoleWithCount, int64, error) {
+
+	db := common.DBFromContext(ctx)
+	tx := db.Model(&model.SysRole{})
+
+	// 查询数量
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	// 关联表中可能残留已删除的用户，只统计存在的用户
+	userIds := db.Model(&model.SysUser{}).Select("id")
+	userCounts := db.Model(&model.SysUserRole{}).Select("sys_role_id, COUNT(*) AS user_count").
+		Where("sys_user_id IN (?)", userIds).Group("sys_role_id")
+
+	// 没有用户的角色在左连接中没有匹配的统计行，数量为0
+	currentTable := clause.Table{Name: clause.CurrentTable}
+	var roles []response.RoleWithCount
+	if err := tx.Select("?.*, COALESCE(uc.user_count, 0) AS user_count", currentTable).
+		Joins("LEFT JOIN (?) uc ON uc.sys_role_id = ?.id", userCounts, currentTable).
+		Order("id").Limit(size).Offset((page - 1) * size).Find(&roles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return roles, total, nil
+}
+
+// GetUsersByRoleIds 批量查询角色下的用户，按角色id分组返回，用于批量加载多个角色的用户避免逐个查询
+func (*SysRoleService) GetUsersByRoleIds(ctx context.Context, roleIds []uint64) (map[uint64][]model.SysUser, error) {
+
+	db := common.DBFromContext(ctx)
+	var userRoles []model.SysUserRole
+	if err := db.Where("sys_role_id IN ?", roleIds).Find(&userRoles).Error; err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64][]model.SysUser, len(roleIds))
+	if len(userRoles) == 0 {
+		return res, nil
+	}
+
+	userIds := mapset.NewThreadUnsafeSet[uint64]()
+	for i := range userRoles {
+		userIds.Add(userRoles[i].SysUserId)
+	}
+	var users []model.SysUser
+	if err := db.Where("id IN ?", userIds.ToSlice()).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	userMap := make(map[uint64]*model.SysUser, len(users))
+	for i := range users {
+		userMap[users[i].Id] = &users[i]
+	}
+	for i := range userRoles {
+		// 关联表中可能残留已删除的用户
+		if user, ok := userMap[userRoles[i].SysUserId]; ok {
+			res[userRoles[i].SysRoleId] = append(res[userRoles[i].SysRoleId], *user)
+		}
+	}
+	return res, nil
+}
+
+// GetMenusByRoleIds 批量查询角色直接绑定的菜单（不包含继承的菜单），按角色id分组返回
+func (*SysRoleService) GetMenusByRoleIds(ctx context.Context, roleIds []uint64) (map[uint64][]model.SysMenu, error) {
+
+	db := common.DBFromContext(ctx)
+	var roleMenus []model.SysRoleMenu
+	if err := db.Where("sys_role_id IN ?", roleIds).Find(&roleMenus).Error; err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64][]model.SysMenu, len(roleIds))
+	if len(roleMenus) == 0 {
+		return res, nil
+	}
+
+	menuIds := mapset.NewThreadUnsafeSet[uint64]()
+	for i := range roleMenus {
+		menuIds.Add(roleMenus[i].SysMenuId)
+	}
+	var menus []model.SysMenu
+	if err := db.Where("id IN ?", menuIds.ToSlice()).Order("id").Find(&menus).Error; err != nil {
+		return nil, err
+	}
+
+	menuMap := make(map[uint64]*model.SysMenu, len(menus))
+	for i := range menus {
+		menuMap[menus[i].Id] = &menus[i]
+	}
+	for i := range roleMenus {
+		if menu, ok := menuMap[roleMenus[i].SysMenuId]; ok {
+			res[roleMenus[i].SysRoleId] = append(res[roleMenus[i].SysRoleId], *menu)
+		}
+	}
+	return res, nil
+}
+
+// AddUsersToRole 为角色批量添加用户，直接写入关联表，已关联的用户会被跳过
+func (roleService *SysRoleService) AddUsersToRole(ctx context.Context, roleId uint64, userIds []uint64) error {
+
+	userIds = mapset.NewSet(userIds...).ToSlice()
+
+	var policies policyChanges
+	err := common.DBFromContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if err := roleService.authorizeById(ctx, tx, roleId, RoleActionAssignUsers); err != nil {
+			return err
+		}
+
+		var existUserIds []uint64
+		if err := tx.Model(&model.SysUser{}).Where("id IN ?", userIds).Pluck("id", &existUserIds).Error; err != nil {
+			return err
+		}
+		if len(existUserIds) != len(userIds) {
+			notExistUsers := mapset.NewThreadUnsafeSet(userIds...).Difference(mapset.NewThreadUnsafeSet(existUserIds...))
+			return buserr.NewNoticeBusErr(fmt.Sprintf("用户不存在：%v", notExistUsers.ToSlice()))
+		}
+
+		userRoles := make([]model.SysUserRole, 0, len(userIds))
+		for i := range userIds {
+			userRoles = append(userRoles, model.SysUserRole{SysRoleId: roleId, SysUserId: userIds[i]})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&userRoles).Error; err != nil {
+			return err
+		}
+		if err := incrTokenVersion(tx, userIds); err != nil {
+			return err
+		}
+
+		policies.add(func() error { return CasbinService.AddUsersForRole(roleId, userIds) })
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	policies.applyAfterCommit(ctx)
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	return nil
+}
+
+// RemoveUsersFromRole 批量移除角色下的用户，直接删除关联表数据
+func (roleService *SysRoleService) RemoveUsersFromRole(ctx context.Context, roleId uint64, userIds []uint64) error {
+
+	userIds = mapset.NewSet(userIds...).ToSlice()
+
+	var policies policyChanges
+	err := common.DBFromContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		if err := roleService.authorizeById(ctx, tx, roleId, RoleActionAssignUsers); err != nil {
+			return err
+		}
+
+		if err := tx.Where("sys_role_id = ? AND sys_user_id IN ?", roleId, userIds).Delete(&model.SysUserRole{}).Error; err != nil {
+			return err
+		}
+		if err := incrTokenVersion(tx, userIds); err != nil {
+			return err
+		}
+
+		policies.add(func() error { return CasbinService.DeleteUsersForRole(roleId, userIds) })
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	policies.applyAfterCommit(ctx)
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	return nil
+}
+
+// AllSimpleRoles 获取角色列表（用户管理页面分配用户角色时展示使用）
+func (*SysRoleService) AllSimpleRoles(ctx context.Context) (roles []*response.SysSimpleRoleResp, err error) {
+
+	roles = make([]*response.SysSimpleRoleResp, 0)
+	err = common.DBFromContext(ctx).Model(&model.SysRole{}).Select("id, name, code").Where("status = ?", 1).Find(&roles).Error
+	return
+}
+
+// BuildDataScopeClause 根据角色的数据权限范围构建查询条件，作用于包含 dept_id 字段的用户数据
+func (roleService *SysRoleService) BuildDataScopeClause(role *model.SysRole, userDeptId uint64) func(*gorm.DB) *gorm.DB {
+	return roleService.BuildUserDataScopeClause([]model.SysRole{*role}, userDeptId)
+}
+
+// BuildUserDataScopeClause 根据用户拥有的多个角色构建数据权限查询条件，各角色的数据范围取并集
+func (roleService *SysRoleService) BuildUserDataScopeClause(roles []model.SysRole, userDeptId uint64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		// 未分配角色时不允许查看任何数据
+		if len(roles) == 0 {
+			return db.Where("1 = 0")
+		}
+
+		var conditions []string
+		var args []any
+		for i := range roles {
+			condition, conditionArgs, err := roleService.dataScopeCondition(db, &roles[i], userDeptId)
+			if err != nil {
+				_ = db.AddError(err)
+				return db
+			}
+			// 任一角色拥有全部数据权限时不做过滤
+			if condition == "" {
+				return db
+			}
+			conditions = append(conditions, "("+condition+")")
+			args = append(args, conditionArgs...)
+		}
+
+		return db.Where(strings.Join(conditions, " OR "), args...)
+	}
+}
+
+// dataScopeCondition 获取单个角色的数据权限查询条件，返回空条件时表示拥有全部数据权限，
+// 未知的数据权限范围不允许查看任何数据
+func (*SysRoleService) dataScopeCondition(db *gorm.DB, role *model.SysRole, userDeptId uint64) (string, []any, error) {
+	switch role.DataScope {
+	case model.DataScopeAll:
+		return "", nil, nil
+	case model.DataScopeDeptAndSub:
+		var depts []*model.SysDept
+		if err := db.Session(&gorm.Session{NewDB: true}).Model(&model.SysDept{}).Select("id, parent_id").Find(&depts).Error; err != nil {
+			return "", nil, err
+		}
+		return dataScopeDeptColumn + " IN ?", []any{collectSubDeptIds(depts, userDeptId)}, nil
+	case model.DataScopeDept:
+		return dataScopeDeptColumn + " = ?", []any{userDeptId}, nil
+	case model.DataScopeSelf:
+		ctx := db.Statement.Context
+		return dataScopeUserColumn + " = ?", []any{common.USER_CTX.GetUserId(&ctx)}, nil
+	case model.DataScopeCustom:
+		deptIds := db.Session(&gorm.Session{NewDB: true}).Model(&model.SysRoleDept{}).Select("sys_dept_id").Where("sys_role_id = ?", role.Id)
+		return dataScopeDeptColumn + " IN (?)", []any{deptIds}, nil
+	default:
+		return "1 = 0", nil, nil
+	}
+}
+
+// assignRoleDepts 分配自定义数据权限的部门，非自定义数据权限时清空关联的部门
+func (*SysRoleService) assignRoleDepts(tx *gorm.DB, role *model.SysRole, deptIds []uint64) error {
+	if err := tx.Model(&model.SysRoleDept{}).Where("sys_role_id = ?", role.Id).Delete(&model.SysRoleDept{}).Error; err != nil {
+		return err
+	}
+	if role.DataScope != model.DataScopeCustom || len(deptIds) == 0 {
+		return nil
+	}
+
+	roleDepts := make([]model.SysRoleDept, 0, len(deptIds))
+	for _, deptId := range mapset.NewSet(deptIds...).ToSlice() {
+		roleDepts = append(roleDepts, model.SysRoleDept{SysRoleId: role.Id, SysDeptId: deptId})
+	}
+	return tx.Model(&model.SysRoleDept{}).Create(&roleDepts).Error
+}
+
+// collectSubDeptIds 获取部门及其所有子部门的id
+func collectSubDeptIds(depts []*model.SysDept, deptId uint64) []uint64 {
+	children := make(map[uint64][]uint64, len(depts))
+	for i := range depts {
+		children[depts[i].ParentId] = append(children[depts[i].ParentId], depts[i].Id)
+	}
+
+	visited := mapset.NewThreadUnsafeSet(deptId)
+	deptIds := []uint64{deptId}
+	for i := 0; i < len(deptIds); i++ {
+		for _, childId := range children[deptIds[i]] {
+			// 避免脏数据中的环导致死循环
+			if visited.Add(childId) {
+				deptIds = append(deptIds, childId)
+			}
+		}
+	}
+	return deptIds
+}
+
+// 校验角色名称和编码是否重复
+func (roleService *SysRoleService) validateDuplicateRole(tx *gorm.DB, role *model.SysRole) error {
+	if err := roleService.validateDuplicateRoleByName(tx, role.Name); err != nil {
+		return err
+	}
+
+	if err := roleService.validateDuplicateRoleByCode(tx, role.Code); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (*SysRoleService) validateDuplicateRoleByName(tx *gorm.DB, name string) error {
+	var count int64
+
+	if err := tx.Where("name = ?", name).Limit(1).Count(&count).Error; err != nil {
 		return err
 	}
 	if count > 0 {
-		return buserr.NewNoticeBusErr("角色编码已存在！")
+		validErr := &buserr.ValidationError{}
+		validErr.AddMessage("name", i18n.RoleNameExists)
+		return validErr
 	}
 
 	return nil
 }
+
+// validateDuplicateRoleByCode 校验角色编码是否重复，编码不区分大小写。
+// sqlite 等数据库的比较区分大小写，因此不依赖数据库排序规则，统一在代码中校验
+func (*SysRoleService) validateDuplicateRoleByCode(tx *gorm.DB, code string) error {
+	var count int64
+
+	if err := tx.Where("LOWER(code) = LOWER(?)", code).Limit(1).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return buserr.ErrRoleCodeConflict
+	}
+
+	return nil
+}
+
+// translateRoleError 将角色写入时的数据库错误转换为角色业务错误，
+// 并发写入时校验可能通过，由数据库唯一约束兜底
+func translateRoleError(err error) error {
+	switch err = common.TranslateDBError(err); {
+	case errors.Is(err, buserr.ErrDuplicateKey):
+		return buserr.ErrRoleCodeConflict
+	case errors.Is(err, buserr.ErrNotFound):
+		return buserr.ErrRoleNotFound
+	}
+	return err
+}
+
+// newRoleEvent 创建角色变更事件，操作人从上下文中获取
+func newRoleEvent(ctx context.Context, role *model.SysRole) event.RoleEvent {
+	return event.RoleEvent{RoleId: role.Id, Code: role.Code, Name: role.Name, By: common.USER_CTX.GetUserId(&ctx), At: time.Now()}
+}
+
+// publishRoleEvent 事务提交后发布角色变更事件，事务回滚时不发布
+func publishRoleEvent(ctx context.Context, e event.Event) {
+	common.AfterCommit(ctx, func() { event.Bus.Publish(ctx, e) })
+}
+
+// normalizeRoleCode 规范化角色编码，编码统一去除首尾空格并以小写存储
+func normalizeRoleCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// isReservedRole 是否为内置的超级管理员角色
+func isReservedRole(role *model.SysRole) bool {
+	return normalizeRoleCode(role.Code) == model.SuperAdminRoleCode
+}
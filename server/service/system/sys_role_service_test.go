@@ -0,0 +1,225 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"gitee.com/nichanghao/gdmin/web/response"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRequestTxRollbackKeepsCasbinPolicies(t *testing.T) {
+	db := setupTestDB(t)
+
+	parent := model.SysRole{Name: "父角色", Code: "parent", Status: 1}
+	db.Create(&parent)
+	child := model.SysRole{Name: "子角色", Code: "child", Status: 1}
+	db.Create(&child)
+
+	editReq := &request.SysRoleEditReq{Id: child.Id, SysRoleAddReq: request.SysRoleAddReq{
+		Name: child.Name, Code: child.Code, ParentId: parent.Id,
+	}}
+	hasParent := func() bool {
+		ok, err := global.Enforcer.HasGroupingPolicy(CasbinService.GetCasbinRoleStr(child.Id), CasbinService.GetCasbinRoleStr(parent.Id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	// 请求级事务中后续操作失败，角色的修改回滚，casbin 的继承关系也不修改
+	errLater := errors.New("later step failed")
+	err := common.RunInTx(context.Background(), func(ctx context.Context) error {
+		if err := RoleService.EditRole(&common.Request{Data: editReq, Context: ctx}); err != nil {
+			return err
+		}
+		if hasParent() {
+			t.Error("casbin policy changed before the request transaction committed")
+		}
+		return errLater
+	})
+	if !errors.Is(err, errLater) {
+		t.Fatalf("RunInTx err = %v", err)
+	}
+	var got model.SysRole
+	db.First(&got, child.Id)
+	if got.ParentId != 0 || hasParent() {
+		t.Fatalf("after rollback parentId = %d, casbin parent = %v, want both unset", got.ParentId, hasParent())
+	}
+
+	// 提交后修改 casbin 的继承关系
+	err = common.RunInTx(context.Background(), func(ctx context.Context) error {
+		return RoleService.EditRole(&common.Request{Data: editReq, Context: ctx})
+	})
+	if err != nil {
+		t.Fatalf("EditRole: %v", err)
+	}
+	db.First(&got, child.Id)
+	if got.ParentId != parent.Id || !hasParent() {
+		t.Fatalf("after commit parentId = %d, casbin parent = %v", got.ParentId, hasParent())
+	}
+}
+
+func TestListRolesWithUserCount(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	admin := model.SysRole{Name: "管理员", Code: "admin"}
+	empty := model.SysRole{Name: "无用户", Code: "empty"}
+	stale := model.SysRole{Name: "已删除用户", Code: "stale"}
+	deleted := model.SysRole{Name: "已删除角色", Code: "deleted"}
+	for _, role := range []*model.SysRole{&admin, &empty, &stale, &deleted} {
+		db.Create(role)
+	}
+	db.Delete(&deleted)
+
+	db.Create(&model.SysUser{Username: "u1", Roles: []model.SysRole{admin}})
+	db.Create(&model.SysUser{Username: "u2", Roles: []model.SysRole{admin, stale}})
+	// 逻辑删除的用户及关联表中残留的不存在的用户不统计
+	softDeleted := model.SysUser{Username: "u3", Roles: []model.SysRole{stale}}
+	db.Create(&softDeleted)
+	db.Delete(&softDeleted)
+	db.Create(&model.SysUserRole{SysRoleId: stale.Id, SysUserId: 999})
+
+	roles, total, err := RoleService.ListRolesWithUserCount(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("ListRolesWithUserCount: %v", err)
+	}
+	if total != 3 || len(roles) != 3 {
+		t.Fatalf("total = %d, roles = %+v, want 3 roles without the deleted one", total, roles)
+	}
+	want := map[string]int64{"admin": 2, "empty": 0, "stale": 1}
+	for _, role := range roles {
+		if count, ok := want[role.Code]; !ok || role.UserCount != count {
+			t.Errorf("role %s user count = %d, want %d", role.Code, role.UserCount, count)
+		}
+		if role.Name == "" {
+			t.Errorf("role %s has no name loaded", role.Code)
+		}
+	}
+
+	// 分页
+	roles, total, err = RoleService.ListRolesWithUserCount(ctx, 2, 2)
+	if err != nil || total != 3 || len(roles) != 1 || roles[0].Code != "stale" || roles[0].UserCount != 1 {
+		t.Fatalf("page 2 = %+v, total %d, err %v", roles, total, err)
+	}
+}
+
+func TestRestoreRolePublishesRestoredEvent(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	role := model.SysRole{Name: "运维", Code: "ops", Status: 1}
+	db.Create(&role)
+	if _, err := RoleService.DeleteRole(&common.Request{Data: &request.SysRoleDeleteReq{Id: role.Id}, Context: ctx}); err != nil {
+		t.Fatalf("DeleteRole: %v", err)
+	}
+
+	topics := captureEvents(t)
+	if err := RoleService.RestoreRole(&common.Request{Data: &request.QueryIdReq{Id: role.Id}, Context: ctx}); err != nil {
+		t.Fatalf("RestoreRole: %v", err)
+	}
+	if got := topics(); !slices.Equal(got, []string{"role.restored"}) {
+		t.Fatalf("published events = %v, want [role.restored]", got)
+	}
+}
+
+func TestImportRolesJsonOverwriteClearsParent(t *testing.T) {
+	db := setupTestDB(t)
+
+	parent := model.SysRole{Name: "父角色", Code: "parent", Status: 1}
+	db.Create(&parent)
+	child := model.SysRole{Name: "子角色", Code: "child", Status: 1, ParentId: parent.Id}
+	db.Create(&child)
+	if err := CasbinService.SetRoleParent(child.Id, parent.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	// 导入文件中没有父角色编码时，覆盖的角色不再继承原来的父角色
+	data := `{"roles":[{"name":"子角色","code":"child","status":1}]}`
+	if _, err := RoleService.ImportRolesJson(context.Background(), []byte(data), request.SysRoleImportJsonReq{OnConflict: RoleConflictOverwrite}); err != nil {
+		t.Fatalf("ImportRolesJson: %v", err)
+	}
+	var got model.SysRole
+	db.First(&got, child.Id)
+	if got.ParentId != 0 {
+		t.Fatalf("parent_id = %d after overwrite without parentCode, want 0", got.ParentId)
+	}
+	ok, err := global.Enforcer.HasGroupingPolicy(CasbinService.GetCasbinRoleStr(child.Id), CasbinService.GetCasbinRoleStr(parent.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("casbin parent kept after overwrite without parentCode")
+	}
+}
+
+func TestDeleteRoleDryRunInUse(t *testing.T) {
+	db := setupTestDB(t)
+
+	role := model.SysRole{Name: "运维", Code: "ops", Status: 1}
+	db.Create(&role)
+	db.Create(&model.SysUser{Username: "u1", Roles: []model.SysRole{role}})
+	db.Create(&model.SysUser{Username: "u2", Roles: []model.SysRole{role}})
+	db.Create(&model.SysRoleMenu{SysRoleId: role.Id, SysMenuId: 1})
+
+	// 非强制预览时返回删除的影响及失败原因，不修改数据
+	ctx := i18n.WithLocale(context.Background(), "en")
+	res, err := RoleService.DeleteRole(&common.Request{Data: &request.SysRoleDeleteReq{Id: role.Id, DryRun: true}, Context: ctx})
+	if err != nil {
+		t.Fatalf("DeleteRole dry run: %v", err)
+	}
+	if res.Deleted != 0 || res.UserBindings != 2 || res.MenuBindings != 1 || len(res.Roles) != 1 {
+		t.Fatalf("dry run result = %+v", res)
+	}
+	want := buserr.Localize(buserr.NewRoleInUseErr(2), "en")
+	if len(res.Failed) != 1 || res.Failed[0].Id != role.Id || res.Failed[0].Message != want {
+		t.Fatalf("dry run failures = %+v, want %q", res.Failed, want)
+	}
+	if count := db.Model(&role).Association("Users").Count(); count != 2 {
+		t.Fatalf("role users = %d after dry run, want 2", count)
+	}
+
+	// 实际删除时仍然返回角色已分配的错误
+	if _, err = RoleService.DeleteRole(&common.Request{Data: &request.SysRoleDeleteReq{Id: role.Id}, Context: ctx}); !errors.Is(err, buserr.ErrRoleInUse) {
+		t.Fatalf("DeleteRole err = %v, want ErrRoleInUse", err)
+	}
+}
+
+func TestImportRolesLocalizedRowErrors(t *testing.T) {
+	db := setupTestDB(t)
+	db.Create(&model.SysRole{Name: "运维", Code: "ops", Status: 1})
+
+	csv := "name,code\nonly-name\n,empty\n测试,bad code\n审计,audit\n审计,audit2\n稽核,audit\n运维,ops2\n开发,ops\n"
+	ctx := i18n.WithLocale(context.Background(), "en")
+	imported, rowErrs, err := RoleService.ImportRoles(ctx, strings.NewReader(csv), false)
+	if err != nil || imported != 1 {
+		t.Fatalf("ImportRoles = %d, %v", imported, err)
+	}
+	want := []response.RowError{
+		{Row: 2, Message: "Missing role name or code"},
+		{Row: 3, Message: "Role name is required"},
+		{Row: 4, Message: "Role code may only contain letters, digits, underscores, colons and hyphens"},
+		{Row: 6, Message: "Duplicate role name in the file"},
+		{Row: 7, Message: "Duplicate role code in the file"},
+		{Row: 8, Message: "Role name already exists"},
+		{Row: 9, Message: "Role code already exists"},
+	}
+	if !slices.Equal(rowErrs, want) {
+		t.Fatalf("row errors = %+v, want %+v", rowErrs, want)
+	}
+
+	// 新增角色时名称重复的错误同样按语言翻译
+	err = RoleService.AddRole(&common.Request{Data: &request.SysRoleAddReq{Name: "运维", Code: "ops3"}, Context: ctx})
+	var validErr *buserr.ValidationError
+	if !errors.As(err, &validErr) || validErr.Localize("en").Error() != "Role name already exists" {
+		t.Fatalf("AddRole with a duplicate name err = %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+)
+
+// SwapRoleCodes 在同一事务中交换两个角色的编码，任一角色不存在时返回 ErrRoleNotFound 并回滚。
+// 先将其中一个角色的编码改为临时编码再依次交换，避免交换过程中编码重复；
+// casbin 中的角色主体为角色id，交换编码不需要修改策略，但 token 中携带的角色编码会过期，两个角色下的用户需重新获取token
+func (roleService *SysRoleService) SwapRoleCodes(ctx context.Context, idA, idB uint64) error {
+
+	if idA == idB {
+		validErr := &buserr.ValidationError{}
+		validErr.AddMessage("idB", i18n.RoleSwapSelf)
+		return validErr
+	}
+
+	var rolesOld, rolesNew []model.SysRole
+	var userIds []uint64
+	err := common.ModelDB(ctx, &model.SysRole{}).Transaction(func(tx *gorm.DB) error {
+
+		// 1. 按id顺序锁定两个角色，避免并发交换时死锁
+		q := tx.Where("id IN ?", []uint64{idA, idB}).Order("id")
+		if supportsRowLocking(tx) {
+			q = q.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
+		}
+		if err := q.Find(&rolesOld).Error; err != nil {
+			return err
+		}
+		if len(rolesOld) != 2 {
+			return buserr.ErrRoleNotFound
+		}
+		if isReservedRole(&rolesOld[0]) || isReservedRole(&rolesOld[1]) {
+			return buserr.ErrReservedRole
+		}
+		// 交换前后的角色都需要校验授权策略
+		for i := range rolesOld {
+			swapped := rolesOld[i]
+			swapped.Code = rolesOld[1-i].Code
+			if err := roleService.authorize(ctx, &rolesOld[i], RoleActionUpdate); err != nil {
+				return err
+			}
+			if err := roleService.authorize(ctx, &swapped, RoleActionUpdate); err != nil {
+				return err
+			}
+		}
+
+		// 2. 通过临时编码交换
+		first, second := &rolesOld[0], &rolesOld[1]
+		if err := tx.Model(&model.SysRole{}).Where("id = ?", first.Id).
+			Update("code", "~swap:"+strconv.FormatUint(first.Id, 10)).Error; err != nil {
+			return err
+		}
+		if err := updateRoleCode(tx, second.Id, first.Code); err != nil {
+			return err
+		}
+		if err := updateRoleCode(tx, first.Id, second.Code); err != nil {
+			return err
+		}
+
+		// 3. 查询修改后的数据用于记录操作日志，并使两个角色下用户的token失效
+		if err := tx.Where("id IN ?", []uint64{idA, idB}).Order("id").Find(&rolesNew).Error; err != nil {
+			return err
+		}
+		if err := recordRoleHistory(ctx, tx, model.OperationUpdate, idA, idB); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.SysUserRole{}).Distinct("sys_user_id").
+			Where("sys_role_id IN ?", []uint64{idA, idB}).Pluck("sys_user_id", &userIds).Error; err != nil {
+			return err
+		}
+		return incrTokenVersion(tx, userIds)
+	})
+	if err != nil {
+		return translateRoleError(err)
+	}
+
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	for i := range rolesOld {
+		AuditService.RecordRole(ctx, model.OperationUpdate, &rolesOld[i], &rolesNew[i])
+		publishRoleEvent(ctx, event.RoleUpdated{RoleEvent: newRoleEvent(ctx, &rolesNew[i])})
+	}
+	return nil
+}
+
+// updateRoleCode 修改角色编码并递增版本号，使持有旧版本号的编辑请求失败
+func updateRoleCode(tx *gorm.DB, roleId uint64, code string) error {
+	return tx.Model(&model.SysRole{}).Where("id = ?", roleId).
+		Updates(map[string]any{"code": code, "version": gorm.Expr("version + ?", 1)}).Error
+}
@@ -0,0 +1,48 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/model"
+	"testing"
+)
+
+func TestSwapRoleCodes(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	ops := model.SysRole{Name: "运维", Code: "ops", Status: 1}
+	db.Create(&ops)
+	audit := model.SysRole{Name: "审计", Code: "audit", Status: 1}
+	db.Create(&audit)
+	codes := func() (string, string) {
+		var a, b model.SysRole
+		db.First(&a, ops.Id)
+		db.First(&b, audit.Id)
+		return a.Code, b.Code
+	}
+
+	if err := RoleService.SwapRoleCodes(ctx, ops.Id, audit.Id); err != nil {
+		t.Fatalf("SwapRoleCodes: %v", err)
+	}
+	if a, b := codes(); a != "audit" || b != "ops" {
+		t.Fatalf("codes after swap = %s, %s, want audit, ops", a, b)
+	}
+
+	// 任一角色不存在时不修改另一个角色
+	if err := RoleService.SwapRoleCodes(ctx, ops.Id, audit.Id+100); !errors.Is(err, buserr.ErrRoleNotFound) {
+		t.Fatalf("SwapRoleCodes with a missing role err = %v, want ErrRoleNotFound", err)
+	}
+	if a, b := codes(); a != "audit" || b != "ops" {
+		t.Fatalf("codes after a failed swap = %s, %s, want audit, ops", a, b)
+	}
+
+	var validErr *buserr.ValidationError
+	if err := RoleService.SwapRoleCodes(ctx, ops.Id, ops.Id); !errors.As(err, &validErr) {
+		t.Fatalf("SwapRoleCodes with itself err = %v, want *buserr.ValidationError", err)
+	}
+	if msg := validErr.Localize("en").Error(); msg != "A role cannot swap codes with itself" {
+		t.Fatalf("localized message = %q", msg)
+	}
+}
@@ -0,0 +1,405 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"gitee.com/nichanghao/gdmin/web/response"
+	mapset "github.com/deckarep/golang-set/v2"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// 导入角色时角色编码已存在的处理方式
+const (
+	RoleConflictError     = "error"     // 返回错误，不导入任何角色
+	RoleConflictSkip      = "skip"      // 跳过已存在的角色
+	RoleConflictOverwrite = "overwrite" // 覆盖已存在的角色及其菜单
+)
+
+// RoleExportData 角色导出数据，用于在不同环境之间迁移角色
+type RoleExportData struct {
+	Roles []RoleExportItem `json:"roles"`
+}
+
+// RoleExportItem 导出的角色，菜单和父角色均使用编码表示，不依赖各环境的数据库id。
+// 自定义数据权限的部门与环境相关，不会被导出
+type RoleExportItem struct {
+	Name       string   `json:"name"`                 // 角色名称
+	Code       string   `json:"code"`                 // 角色编码
+	Status     uint8    `json:"status"`               // 状态(1:启用 2:禁用)
+	Desc       string   `json:"desc"`                 // 备注
+	DataScope  int8     `json:"dataScope"`            // 数据权限范围
+	ParentCode string   `json:"parentCode,omitempty"` // 父角色编码
+	Menus      []string `json:"menus"`                // 菜单编码，按钮使用权限标识，目录和菜单使用路由名称
+}
+
+// ExportRoles 导出角色及其绑定的菜单，ids 为空时导出全部角色
+func (*SysRoleService) ExportRoles(ctx context.Context, ids []uint64) ([]byte, error) {
+
+	db := common.DBFromContext(ctx)
+	var roles []model.SysRole
+	tx := db.Model(&model.SysRole{}).Order("id")
+	if len(ids) > 0 {
+		tx = tx.Where("id IN ?", ids)
+	}
+	if err := tx.Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	roleIds := make([]uint64, 0, len(roles))
+	for i := range roles {
+		roleIds = append(roleIds, roles[i].Id)
+	}
+
+	// 父角色编码
+	var parents []model.SysRole
+	parentIds := db.Model(&model.SysRole{}).Select("parent_id").Where("id IN ?", roleIds)
+	if err := db.Model(&model.SysRole{}).Select("id, code").Where("id IN (?)", parentIds).Find(&parents).Error; err != nil {
+		return nil, err
+	}
+	parentCodes := make(map[uint64]string, len(parents))
+	for i := range parents {
+		parentCodes[parents[i].Id] = parents[i].Code
+	}
+
+	// 角色绑定的菜单编码
+	var roleMenus []model.SysRoleMenu
+	if err := db.Model(&model.SysRoleMenu{}).Where("sys_role_id IN ?", roleIds).Find(&roleMenus).Error; err != nil {
+		return nil, err
+	}
+	var menus []model.SysMenu
+	menuIds := db.Model(&model.SysRoleMenu{}).Select("sys_menu_id").Where("sys_role_id IN ?", roleIds)
+	if err := db.Model(&model.SysMenu{}).Select("id, route_name, permission").Where("id IN (?)", menuIds).Find(&menus).Error; err != nil {
+		return nil, err
+	}
+	menuCodes := make(map[uint64]string, len(menus))
+	for i := range menus {
+		menuCodes[menus[i].Id] = menuCode(&menus[i])
+	}
+	roleMenuCodes := make(map[uint64][]string, len(roles))
+	for i := range roleMenus {
+		if code := menuCodes[roleMenus[i].SysMenuId]; code != "" {
+			roleMenuCodes[roleMenus[i].SysRoleId] = append(roleMenuCodes[roleMenus[i].SysRoleId], code)
+		}
+	}
+
+	data := RoleExportData{Roles: make([]RoleExportItem, 0, len(roles))}
+	for i := range roles {
+		item := RoleExportItem{
+			Name:       roles[i].Name,
+			Code:       roles[i].Code,
+			Status:     roles[i].Status,
+			Desc:       roles[i].Desc,
+			DataScope:  roles[i].DataScope,
+			ParentCode: parentCodes[roles[i].ParentId],
+			Menus:      roleMenuCodes[roles[i].Id],
+		}
+		if item.Menus == nil {
+			item.Menus = make([]string, 0)
+		}
+		data.Roles = append(data.Roles, item)
+	}
+
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// ImportRolesJson 导入 ExportRoles 导出的角色，按角色编码新增或更新角色，按菜单编码绑定菜单，
+// 所有角色在同一事务中导入，任一角色导入失败时全部回滚
+func (roleService *SysRoleService) ImportRolesJson(ctx context.Context, data []byte, opts request.SysRoleImportJsonReq) (*response.SysRoleJsonImportResp, error) {
+
+	var importData RoleExportData
+	if err := json.Unmarshal(data, &importData); err != nil {
+		return nil, buserr.NewNoticeBusErr("导入文件格式错误！")
+	}
+	if opts.OnConflict == "" {
+		opts.OnConflict = RoleConflictError
+	}
+
+	items, err := roleService.validateImportItems(importData.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.SysRoleJsonImportResp{Skipped: make([]string, 0)}
+	var userIds []uint64
+	var events []event.Event
+	var policies policyChanges
+	err = common.DBFromContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// 1. 根据编码解析菜单
+		menus, err := roleService.resolveImportMenus(tx, items)
+		if err != nil {
+			return err
+		}
+
+		// 2. 查询已存在的角色
+		codes := make([]string, 0, len(items))
+		for i := range items {
+			codes = append(codes, items[i].Code)
+		}
+		var existRoles []model.SysRole
+		if err = tx.Model(&model.SysRole{}).Where("LOWER(code) IN ?", codes).Find(&existRoles).Error; err != nil {
+			return err
+		}
+		existRoleMap := make(map[string]*model.SysRole, len(existRoles))
+		for i := range existRoles {
+			existRoleMap[normalizeRoleCode(existRoles[i].Code)] = &existRoles[i]
+		}
+		if len(existRoles) > 0 && opts.OnConflict == RoleConflictError {
+			existCodes := make([]string, 0, len(existRoles))
+			for i := range existRoles {
+				existCodes = append(existCodes, existRoles[i].Code)
+			}
+			return buserr.NewNoticeBusErr(fmt.Sprintf("角色编码已存在：%v", existCodes))
+		}
+
+		// 3. 新增或更新角色并绑定菜单
+		roleIds := make(map[string]uint64, len(items))
+		var createdIds, updatedIds []uint64
+		rolesBefore := make(map[uint64]*model.SysRole)
+		for i := range items {
+			item := &items[i]
+			role, exist := existRoleMap[item.Code]
+			if exist && opts.OnConflict == RoleConflictSkip {
+				res.Skipped = append(res.Skipped, item.Code)
+				continue
+			}
+
+			if exist {
+				if err = roleService.authorize(ctx, role, RoleActionUpdate); err != nil {
+					return err
+				}
+				if err = roleService.overwriteImportRole(tx, role, item); err != nil {
+					return err
+				}
+				// 角色状态变更后，拥有该角色的用户需重新获取token
+				if role.Status != item.Status {
+					ids, err2 := roleUserIds(tx, role.Id)
+					if err2 != nil {
+						return err2
+					}
+					userIds = append(userIds, ids...)
+				}
+				events = append(events, event.RoleUpdated{RoleEvent: newRoleEvent(ctx, role)})
+				updatedIds = append(updatedIds, role.Id)
+				rolesBefore[role.Id] = role
+				res.Updated++
+			} else {
+				role = &model.SysRole{Name: item.Name, Code: item.Code, Status: item.Status, Desc: item.Desc, DataScope: item.DataScope}
+				if err = roleService.authorize(ctx, role, RoleActionCreate); err != nil {
+					return err
+				}
+				if err = roleService.validateDuplicateRoleByName(tx.Model(&model.SysRole{}), role.Name); err != nil {
+					return err
+				}
+				if err = tx.Model(&model.SysRole{}).Create(role).Error; err != nil {
+					return err
+				}
+				events = append(events, event.RoleCreated{RoleEvent: newRoleEvent(ctx, role)})
+				createdIds = append(createdIds, role.Id)
+				res.Created++
+			}
+			roleIds[item.Code] = role.Id
+
+			roleMenus := make([]model.SysMenu, 0, len(item.Menus))
+			for _, code := range item.Menus {
+				roleMenus = append(roleMenus, menus[code])
+			}
+			if err = roleService.bindRoleMenus(tx, role.Id, roleMenus, &policies); err != nil {
+				return err
+			}
+		}
+
+		// 4. 根据父角色编码设置继承关系
+		if err = roleService.bindImportParents(tx, items, roleIds, &policies); err != nil {
+			return err
+		}
+
+		// 5. 保存角色的历史版本
+		if err = recordRoleHistory(ctx, tx, model.OperationCreate, createdIds...); err != nil {
+			return err
+		}
+		if err = recordRoleHistory(ctx, tx, model.OperationUpdate, updatedIds...); err != nil {
+			return err
+		}
+
+		// 6. 记录操作日志，更新的角色记录导入前后的差异
+		if len(createdIds)+len(updatedIds) > 0 {
+			var rolesAfter []model.SysRole
+			importedIds := append(append(make([]uint64, 0, len(createdIds)+len(updatedIds)), createdIds...), updatedIds...)
+			if err = tx.Model(&model.SysRole{}).Where("id IN ?", importedIds).Order("id").Find(&rolesAfter).Error; err != nil {
+				return err
+			}
+			for i := range rolesAfter {
+				before, action := rolesBefore[rolesAfter[i].Id], model.OperationUpdate
+				if before == nil {
+					action = model.OperationCreate
+				}
+				if err = AuditService.RecordRoleTx(ctx, tx, action, before, &rolesAfter[i]); err != nil {
+					return err
+				}
+			}
+		}
+
+		return incrTokenVersion(tx, userIds)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	policies.applyAfterCommit(ctx)
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	for _, e := range events {
+		publishRoleEvent(ctx, e)
+	}
+	return res, nil
+}
+
+// validateImportItems 校验导入的角色，返回规范化编码后的角色
+func (*SysRoleService) validateImportItems(items []RoleExportItem) ([]RoleExportItem, error) {
+	if len(items) == 0 {
+		return nil, buserr.NewNoticeBusErr("导入文件中没有角色数据！")
+	}
+
+	codes := mapset.NewThreadUnsafeSet[string]()
+	for i := range items {
+		items[i].Code = normalizeRoleCode(items[i].Code)
+		items[i].ParentCode = normalizeRoleCode(items[i].ParentCode)
+		items[i].Name = strings.TrimSpace(items[i].Name)
+		role := model.SysRole{Name: items[i].Name, Code: items[i].Code, Desc: items[i].Desc, DataScope: items[i].DataScope}
+		if msg := validateImportRole(&role, i18n.DefaultLocale); msg != "" {
+			return nil, buserr.NewNoticeBusErr(fmt.Sprintf("第%d个角色：%s", i+1, msg))
+		}
+		if !codes.Add(items[i].Code) {
+			return nil, buserr.NewNoticeBusErr(fmt.Sprintf("导入文件中角色编码重复：%s", items[i].Code))
+		}
+		if items[i].Status == 0 {
+			items[i].Status = 1
+		}
+		// 超出范围的数据权限范围已在校验时拒绝，未设置时与字段默认值一致
+		if items[i].DataScope == 0 {
+			items[i].DataScope = model.DataScopeAll
+		}
+	}
+	return items, nil
+}
+
+// resolveImportMenus 根据菜单编码查询菜单，存在无法解析的菜单编码时返回错误
+func (*SysRoleService) resolveImportMenus(tx *gorm.DB, items []RoleExportItem) (map[string]model.SysMenu, error) {
+
+	codes := mapset.NewThreadUnsafeSet[string]()
+	for i := range items {
+		codes.Append(items[i].Menus...)
+	}
+	menus := make(map[string]model.SysMenu, codes.Cardinality())
+	if codes.Cardinality() == 0 {
+		return menus, nil
+	}
+
+	var menuList []model.SysMenu
+	if err := tx.Model(&model.SysMenu{}).Select("id, route_name, permission").
+		Where("permission IN ? OR (permission = '' AND route_name IN ?)", codes.ToSlice(), codes.ToSlice()).
+		Find(&menuList).Error; err != nil {
+		return nil, err
+	}
+	for i := range menuList {
+		menus[menuCode(&menuList[i])] = menuList[i]
+	}
+
+	notExistCodes := make([]string, 0)
+	for _, code := range codes.ToSlice() {
+		if _, ok := menus[code]; !ok {
+			notExistCodes = append(notExistCodes, code)
+		}
+	}
+	if len(notExistCodes) > 0 {
+		return nil, buserr.NewNoticeBusErr(fmt.Sprintf("菜单不存在：%v", notExistCodes))
+	}
+	return menus, nil
+}
+
+// overwriteImportRole 使用导入的数据覆盖已存在的角色
+func (roleService *SysRoleService) overwriteImportRole(tx *gorm.DB, role *model.SysRole, item *RoleExportItem) error {
+
+	if role.Name != item.Name {
+		if err := roleService.validateDuplicateRoleByName(tx.Model(&model.SysRole{}), item.Name); err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&model.SysRole{}).Where("id = ?", role.Id).Updates(map[string]any{
+		"name":       item.Name,
+		"status":     item.Status,
+		"desc":       item.Desc,
+		"data_scope": item.DataScope,
+		"version":    gorm.Expr("version + ?", 1),
+	}).Error
+}
+
+// bindImportParents 根据父角色编码设置导入角色的父角色，父角色可以是本次导入的角色或已存在的角色，
+// 没有父角色编码时清除覆盖的角色原有的父角色，使导入后的继承关系与文件一致
+func (roleService *SysRoleService) bindImportParents(tx *gorm.DB, items []RoleExportItem, roleIds map[string]uint64, policies *policyChanges) error {
+
+	parentCodes := make([]string, 0)
+	for i := range items {
+		if _, ok := roleIds[items[i].Code]; ok && items[i].ParentCode != "" {
+			parentCodes = append(parentCodes, items[i].ParentCode)
+		}
+	}
+
+	parentIds := make(map[string]uint64, len(parentCodes))
+	if len(parentCodes) > 0 {
+		var parents []model.SysRole
+		if err := tx.Model(&model.SysRole{}).Select("id, code").Where("LOWER(code) IN ?", parentCodes).Find(&parents).Error; err != nil {
+			return err
+		}
+		for i := range parents {
+			parentIds[normalizeRoleCode(parents[i].Code)] = parents[i].Id
+		}
+	}
+
+	for i := range items {
+		roleId, ok := roleIds[items[i].Code]
+		if !ok {
+			continue
+		}
+		if items[i].ParentCode == "" {
+			result := tx.Model(&model.SysRole{}).Where("id = ? AND parent_id <> 0", roleId).Update("parent_id", 0)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				policies.add(func() error { return CasbinService.SetRoleParent(roleId, 0) })
+			}
+			continue
+		}
+		parentId, ok := parentIds[items[i].ParentCode]
+		if !ok {
+			return buserr.NewNoticeBusErr(fmt.Sprintf("父角色不存在：%s", items[i].ParentCode))
+		}
+		if err := roleService.validateRoleParent(tx, roleId, parentId); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.SysRole{}).Where("id = ?", roleId).Update("parent_id", parentId).Error; err != nil {
+			return err
+		}
+		policies.add(func() error { return CasbinService.SetRoleParent(roleId, parentId) })
+	}
+	return nil
+}
+
+// menuCode 获取菜单编码，按钮使用权限标识，目录和菜单使用路由名称
+func menuCode(menu *model.SysMenu) string {
+	if menu.Permission != "" {
+		return menu.Permission
+	}
+	return menu.RouteName
+}
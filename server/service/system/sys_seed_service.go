@@ -0,0 +1,89 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/cache"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Seed 初始化内置数据：创建超级管理员角色，为其分配所有菜单，并将第一个用户设置为超级管理员。
+// 按角色编码判断角色是否已存在，可重复执行
+func (roleService *SysRoleService) Seed(ctx context.Context) error {
+
+	var userIds []uint64
+	var policies policyChanges
+	err := common.DBFromContext(ctx).Transaction(func(tx *gorm.DB) error {
+
+		// 1. 创建或恢复超级管理员角色
+		var role model.SysRole
+		err := tx.Unscoped().Model(&model.SysRole{}).Where("LOWER(code) = ?", model.SuperAdminRoleCode).First(&role).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			role = model.SysRole{
+				Name:      "超级管理员",
+				Code:      model.SuperAdminRoleCode,
+				Status:    1,
+				Desc:      "超级管理员",
+				DataScope: model.DataScopeAll,
+			}
+			if err = tx.Model(&model.SysRole{}).Create(&role).Error; err != nil {
+				return err
+			}
+			zap.L().Info("创建超级管理员角色", zap.Uint64("roleId", role.Id))
+		case err != nil:
+			return err
+		case role.DeletedAt.Valid:
+			if err = tx.Unscoped().Model(&role).Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+			zap.L().Info("恢复超级管理员角色", zap.Uint64("roleId", role.Id))
+		}
+
+		// 2. 分配所有菜单
+		var menus []model.SysMenu
+		if err = tx.Model(&model.SysMenu{}).Select("id, permission").Find(&menus).Error; err != nil {
+			return err
+		}
+		if err = roleService.bindRoleMenus(tx, role.Id, menus, &policies); err != nil {
+			return err
+		}
+
+		// 3. 第一个用户设置为超级管理员
+		var user model.SysUser
+		err = tx.Model(&model.SysUser{}).Select("id").Order("id").First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			zap.L().Warn("用户不存在，跳过分配超级管理员角色")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.SysUserRole{}).Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.SysUserRole{SysRoleId: role.Id, SysUserId: user.Id})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			userIds = append(userIds, user.Id)
+			if err = incrTokenVersion(tx, userIds); err != nil {
+				return err
+			}
+		}
+
+		policies.add(func() error { return CasbinService.AddUsersForRole(role.Id, []uint64{user.Id}) })
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	policies.applyAfterCommit(ctx)
+	common.AfterCommit(ctx, func() { cache.SysUserCache.DelSysUserTokenVersion(userIds...) })
+	return nil
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"gitee.com/nichanghao/gdmin/cache"
 	"gitee.com/nichanghao/gdmin/common"
 	"gitee.com/nichanghao/gdmin/common/buserr"
@@ -31,9 +32,23 @@ func (userService *SysUserService) Login(user *model.SysUser) (*response.SysUser
 	}
 
 	// 生成token
-	token, err := utils.JWT.GenerateToken(&common.UserClaims{
-		ID: userRes.Id, Username: userRes.Username, NickName: userRes.Nickname,
-	})
+	token, err := userService.generateToken(userRes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.SysUserLoginResp{Token: token, UserInfo: userRes}, nil
+}
+
+// RefreshToken 按用户当前的角色和令牌版本号重新签发token
+func (userService *SysUserService) RefreshToken(userId uint64) (*response.SysUserLoginResp, error) {
+
+	var userRes *model.SysUser
+	if err := global.GormDB.Where("id = ?", userId).First(&userRes).Error; err != nil {
+		return nil, buserr.NewNoticeBusErr("用户不存在！")
+	}
+
+	token, err := userService.generateToken(userRes)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +56,22 @@ func (userService *SysUserService) Login(user *model.SysUser) (*response.SysUser
 	return &response.SysUserLoginResp{Token: token, UserInfo: userRes}, nil
 }
 
+// generateToken 生成用户token，token中携带用户已启用的角色编码
+func (*SysUserService) generateToken(user *model.SysUser) (string, error) {
+
+	roleCodes := make([]string, 0)
+	roleIds := global.GormDB.Model(&model.SysUserRole{}).Select("sys_role_id").Where("sys_user_id = ?", user.Id)
+	if err := global.GormDB.Model(&model.SysRole{}).Where("id IN (?) AND status = ?", roleIds, 1).
+		Pluck("code", &roleCodes).Error; err != nil {
+		return "", err
+	}
+
+	return utils.JWT.GenerateToken(&common.UserClaims{
+		ID: user.Id, Username: user.Username, NickName: user.Nickname,
+		RoleCodes: roleCodes, TokenVersion: user.TokenVersion,
+	})
+}
+
 // GetSelfUserInfo 获取当前用户信息
 func (userService *SysUserService) GetSelfUserInfo(id uint64) (res *model.SysUser, err error) {
 
@@ -52,8 +83,16 @@ func (userService *SysUserService) GetSelfUserInfo(id uint64) (res *model.SysUse
 }
 
 // PageUsers 分页查询用户列表
-func (userService *SysUserService) PageUsers(req *request.SysUserPageReq) (*common.PageResp, error) {
-	tx := global.GormDB.Model(&model.SysUser{}).Limit(req.Limit).Offset(req.Offset)
+func (userService *SysUserService) PageUsers(_req *common.Request) (*common.PageResp, error) {
+	req := _req.Data.(*request.SysUserPageReq)
+
+	// 按当前用户的数据权限过滤
+	dataScope, err := userService.dataScopeClause(_req.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := global.GormDB.WithContext(_req.Context).Model(&model.SysUser{}).Scopes(dataScope).Limit(req.Limit).Offset(req.Offset)
 	if req.Username != "" {
 		tx.Where("username LIKE ?", "%"+req.Username+"%")
 	}
@@ -92,6 +131,18 @@ func (userService *SysUserService) PageUsers(req *request.SysUserPageReq) (*comm
 	return res, nil
 }
 
+// dataScopeClause 获取当前用户的数据权限查询条件
+func (userService *SysUserService) dataScopeClause(ctx context.Context) (func(*gorm.DB) *gorm.DB, error) {
+	var user model.SysUser
+	err := global.GormDB.WithContext(ctx).Select("id, dept_id").Preload("Roles", "status = ?", 1).
+		First(&user, common.USER_CTX.GetUserId(&ctx)).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return RoleService.BuildUserDataScopeClause(user.Roles, user.DeptId), nil
+}
+
 // AddUser 新增用户
 func (userService *SysUserService) AddUser(req *common.Request) error {
 	var user model.SysUser
@@ -163,7 +214,8 @@ func (userService *SysUserService) DeleteUser(req *common.Request) error {
 		cache.SysUserCache.SetSysUserStatus(userId, 0)
 	}()
 
-	return global.GormDB.Transaction(func(tx *gorm.DB) error {
+	var policies policyChanges
+	err := global.GormDB.Transaction(func(tx *gorm.DB) error {
 
 		// 删除用户并同步删除关联的角色
 		if err := tx.WithContext(req.Context).Select("Roles").Delete(&model.SysUser{Id: userId}).Error; err != nil {
@@ -171,12 +223,15 @@ func (userService *SysUserService) DeleteUser(req *common.Request) error {
 		}
 
 		// 删除casbin用户
-		if err := CasbinService.ClearRolesForUser(userId); err != nil {
-			return err
-		}
-
+		policies.add(func() error { return CasbinService.ClearRolesForUser(userId) })
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	policies.applyAfterCommit(req.Context)
+	return nil
 }
 
 // AssignRoles 分配角色给用户
@@ -188,16 +243,20 @@ func (userService *SysUserService) AssignRoles(req *common.Request) error {
 		roles = append(roles, &model.SysRole{Id: assignRole.RoleIds[i]})
 	}
 
-	return global.GormDB.Transaction(func(tx *gorm.DB) error {
+	var policies policyChanges
+	err := global.GormDB.Transaction(func(tx *gorm.DB) error {
+
+		// 用户角色变更后，已签发的token失效
+		if err := incrTokenVersion(tx, []uint64{assignRole.Id}); err != nil {
+			return err
+		}
 
 		// 清空用户关联的旧角色数据
 		if err := tx.Model(&model.SysUser{Id: assignRole.Id}).Association("Roles").Clear(); err != nil {
 			return err
 		}
 		// 删除casbin用户角色
-		if err := CasbinService.ClearRolesForUser(assignRole.Id); err != nil {
-			return err
-		}
+		policies.add(func() error { return CasbinService.ClearRolesForUser(assignRole.Id) })
 
 		// 移除关联的所有角色时，直接返回
 		if len(roles) == 0 {
@@ -208,13 +267,16 @@ func (userService *SysUserService) AssignRoles(req *common.Request) error {
 			return err
 		}
 		// 添加casbin用户角色
-		if err := CasbinService.AddRolesForUser(assignRole.Id, assignRole.RoleIds); err != nil {
-			return err
-		}
-
+		policies.add(func() error { return CasbinService.AddRolesForUser(assignRole.Id, assignRole.RoleIds) })
 		return nil
 	})
+	if err != nil {
+		return err
+	}
 
+	policies.applyAfterCommit(req.Context)
+	common.AfterCommit(req.Context, func() { cache.SysUserCache.DelSysUserTokenVersion(assignRole.Id) })
+	return nil
 }
 
 // UpdateStatus 更新用户状态
@@ -226,3 +288,13 @@ func (userService *SysUserService) UpdateStatus(req *request.SysUserEditReq) err
 
 	return nil
 }
+
+// incrTokenVersion 递增用户的令牌版本号，使用户已签发的token失效，事务提交后需删除令牌版本号缓存
+func incrTokenVersion(tx *gorm.DB, userIds []uint64) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	return tx.Model(&model.SysUser{}).Where("id IN ?", userIds).
+		UpdateColumn("token_version", gorm.Expr("token_version + ?", 1)).Error
+}
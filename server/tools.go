@@ -0,0 +1,8 @@
+//go:build tools
+
+package main
+
+// 代码生成工具依赖
+import (
+	_ "github.com/99designs/gqlgen"
+)
@@ -8,4 +8,12 @@ var (
 	SysMenu = &system.SysMenuController{}
 
 	SysRole = &system.SysRoleController{}
+
+	SysAudit = &system.SysAuditController{}
+
+	SysHealth = &system.SysHealthController{}
+
+	SysGraphql = &system.SysGraphqlController{}
+
+	SysDoc = &system.SysDocController{}
 )
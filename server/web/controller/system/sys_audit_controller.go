@@ -0,0 +1,64 @@
+package system
+
+import (
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/service"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"gitee.com/nichanghao/gdmin/web/response"
+	"github.com/gin-gonic/gin"
+)
+
+type SysAuditController struct{}
+
+// ListRoleLogs 获取角色的操作历史
+func (*SysAuditController) ListRoleLogs(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	if res, err := service.SysAudit.ListRoleLogs(req.Context, req.Data.(*request.QueryIdReq).Id); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(res, c)
+	}
+}
+
+// ListRoleRevisions 获取角色的修订列表
+func (*SysAuditController) ListRoleRevisions(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	if res, err := service.SysAudit.ListRoleRevisions(req.Context, req.Data.(*request.QueryIdReq).Id); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(res, c)
+	}
+}
+
+// GetRoleRevision 获取角色在指定修订或指定时间的完整数据
+func (*SysAuditController) GetRoleRevision(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	revisionReq := req.Data.(*request.SysRoleRevisionReq)
+
+	var res *model.SysRoleHistory
+	var err error
+	switch {
+	case revisionReq.Revision != 0:
+		res, err = service.SysAudit.GetRoleAtRevision(req.Context, revisionReq.Id, revisionReq.Revision)
+	case !revisionReq.At.IsZero():
+		res, err = service.SysAudit.GetRoleAt(req.Context, revisionReq.Id, revisionReq.At)
+	default:
+		err = buserr.NewNoticeBusErr("修订号和查询时间不能同时为空！")
+	}
+
+	if err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(res, c)
+	}
+}
@@ -0,0 +1,42 @@
+package system
+
+import (
+	"gitee.com/nichanghao/gdmin/web/openapi"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type SysDocController struct{}
+
+// swaggerUIPage Swagger UI 页面，静态资源从 CDN 加载
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8"/>
+  <title>gdmin API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css"/>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+  window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>`
+
+// OpenAPI 获取 OpenAPI 3 接口文档
+func (*SysDocController) OpenAPI(c *gin.Context) {
+
+	spec := openapi.JSON()
+	if spec == nil {
+		c.Status(http.StatusServiceUnavailable)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+// SwaggerUI 接口文档页面
+func (*SysDocController) SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
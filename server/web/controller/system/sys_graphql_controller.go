@@ -0,0 +1,43 @@
+package system
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/web/graph"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQL 查询复杂度上限，防止嵌套过深的查询
+const graphqlComplexityLimit = 200
+
+type SysGraphqlController struct{}
+
+var graphqlServer = newGraphqlServer()
+
+func newGraphqlServer() *handler.Server {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(graphqlComplexityLimit))
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+	srv.SetRecoverFunc(graph.RecoverFunc)
+	return srv
+}
+
+// Query GraphQL 接口，权限由各解析器按 REST 接口的权限标识校验
+func (*SysGraphqlController) Query(c *gin.Context) {
+
+	claims, err := common.USER_CTX.GetUserClaims(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	ctx := context.WithValue(c.Request.Context(), common.ClaimsKey, claims)
+	// 每个请求使用独立的数据加载器
+	ctx = graph.WithLoaders(ctx, graph.NewLoaders())
+
+	graphqlServer.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
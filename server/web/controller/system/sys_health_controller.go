@@ -0,0 +1,26 @@
+package system
+
+import (
+	"gitee.com/nichanghao/gdmin/service"
+	"gitee.com/nichanghao/gdmin/web/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type SysHealthController struct{}
+
+// Healthz 存活检查，进程正常即返回200
+func (*SysHealthController) Healthz(c *gin.Context) {
+	response.Ok(c)
+}
+
+// Readyz 就绪检查，依赖不可用时返回503及失败的依赖
+func (*SysHealthController) Readyz(c *gin.Context) {
+
+	res := service.SysHealth.Readiness(c.Request.Context())
+	if !res.Ready {
+		response.Result(http.StatusServiceUnavailable, response.ERROR, res, "NOT READY", c)
+		return
+	}
+	response.OkWithData(res, c)
+}
@@ -77,6 +77,22 @@ func (*SysMenuController) GetSelfPermissionRouters(c *gin.Context) {
 
 }
 
+// GetSelfPermissions 获取自身拥有的路由名称和权限标识
+func (*SysMenuController) GetSelfPermissions(c *gin.Context) {
+
+	claims, err := common.USER_CTX.GetUserClaims(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if data, err2 := service.SysRole.GetUserPermissions(c.Request.Context(), claims.ID); err2 != nil {
+		_ = c.Error(err2)
+	} else {
+		response.OkWithData(data, c)
+	}
+}
+
 // AllSimpleMenuTree 获取所有菜单简要信息（角色管理页面分配角色权限时展示使用）
 func (*SysMenuController) AllSimpleMenuTree(c *gin.Context) {
 	if res, err := service.SysMenu.AllSimpleMenuTree(); err != nil {
@@ -2,10 +2,15 @@ package system
 
 import (
 	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
 	"gitee.com/nichanghao/gdmin/service"
 	"gitee.com/nichanghao/gdmin/web/request"
 	"gitee.com/nichanghao/gdmin/web/response"
 	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
 )
 
 type SysRoleController struct{}
@@ -19,20 +24,43 @@ func (*SysRoleController) PageRoles(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		// 允许空的请求体
 		if err.Error() != "EOF" {
-			_ = c.Error(err)
+			_ = c.Error(err).SetType(gin.ErrorTypeBind)
 			return
 		}
 	}
 	// 初始化默认值
 	req.InitDefaultValue()
 
-	if data, err2 := service.SysRole.PageRoles(&req); err2 != nil {
+	if data, err2 := service.SysRole.PageRoles(c.Request.Context(), &req); err2 != nil {
 		_ = c.Error(err2)
 	} else {
 		response.OkWithData(data, c)
 	}
 }
 
+// CursorRoles 角色列表（游标分页）
+func (*SysRoleController) CursorRoles(c *gin.Context) {
+
+	var req request.SysRoleCursorReq
+
+	// 绑定参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// 允许空的请求体
+		if err.Error() != "EOF" {
+			_ = c.Error(err).SetType(gin.ErrorTypeBind)
+			return
+		}
+	}
+	// 初始化默认值
+	req.InitDefaultValue()
+
+	if data, err := service.SysRole.CursorRoles(c.Request.Context(), &req); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(data, c)
+	}
+}
+
 // AddRole 创建角色
 func (*SysRoleController) AddRole(c *gin.Context) {
 
@@ -58,17 +86,211 @@ func (*SysRoleController) EditRole(c *gin.Context) {
 	}
 }
 
-// DeleteRole 删除角色
+// PatchRole 局部修改角色，只修改请求体中传入的字段
+func (*SysRoleController) PatchRole(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	patchReq := req.Data.(*request.SysRolePatchReq)
+
+	// 路径参数由中间件绑定，请求体在此绑定，未传的字段保持为nil
+	if err := c.ShouldBindJSON(patchReq); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := service.SysRole.PatchRole(req.Context, patchReq); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(true, c)
+	}
+}
+
+// CloneRole 复制角色
+func (*SysRoleController) CloneRole(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	cloneReq := req.Data.(*request.SysRoleCloneReq)
+
+	if role, err := service.SysRole.CloneRole(req.Context, cloneReq.Id, cloneReq.Name, cloneReq.Code); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(role, c)
+	}
+}
+
+// SwapRoleCodes 交换两个角色的编码
+func (*SysRoleController) SwapRoleCodes(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	swapReq := req.Data.(*request.SysRoleSwapCodesReq)
+
+	if err := service.SysRole.SwapRoleCodes(req.Context, swapReq.IdA, swapReq.IdB); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(true, c)
+	}
+}
+
+// DeleteRole 删除角色，dryRun=true 时只预览删除的影响
 func (*SysRoleController) DeleteRole(c *gin.Context) {
 
 	_request, _ := c.Get(common.RequestKey)
-	if err2 := service.SysRole.DeleteRole(_request.(*common.Request)); err2 != nil {
+	if res, err2 := service.SysRole.DeleteRole(_request.(*common.Request)); err2 != nil {
 		_ = c.Error(err2)
+	} else {
+		response.OkWithData(res, c)
+	}
+}
+
+// DeleteRoles 批量删除角色，dryRun=true 时只预览删除的影响
+func (*SysRoleController) DeleteRoles(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	deleteReq := req.Data.(*request.SysRoleBatchDeleteReq)
+
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	res, errs, err := service.SysRole.DeleteRoles(req.Context, deleteReq.Ids, deleteReq.Force, dryRun)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	for _, id := range deleteReq.Ids {
+		if roleErr, ok := errs[id]; ok {
+			res.Failed = append(res.Failed, response.RoleDeleteFailure{Id: id, Message: buserr.Localize(roleErr, i18n.LocaleFrom(req.Context))})
+			delete(errs, id)
+		}
+	}
+	response.OkWithData(res, c)
+}
+
+// RepairRoleJoins 清理角色或用户已不存在的用户角色关联，dryRun=true 时只统计不删除
+func (*SysRoleController) RepairRoleJoins(c *gin.Context) {
+
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	if res, err := service.SysRole.RepairRoleJoins(c.Request.Context(), dryRun); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(res, c)
+	}
+}
+
+// PageDeletedRoles 已删除角色列表（回收站）
+func (*SysRoleController) PageDeletedRoles(c *gin.Context) {
+
+	var req common.PageReq
+
+	// 绑定参数
+	if err := c.ShouldBindJSON(&req); err != nil && err.Error() != "EOF" {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	// 初始化默认值
+	req.InitDefaultValue()
+
+	if data, err := service.SysRole.PageDeletedRoles(c.Request.Context(), &req); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(data, c)
+	}
+}
+
+// RestoreRole 恢复已删除的角色
+func (*SysRoleController) RestoreRole(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	if err := service.SysRole.RestoreRole(_request.(*common.Request)); err != nil {
+		_ = c.Error(err)
 	} else {
 		response.OkWithData(true, c)
 	}
 }
 
+// ImportRoles 从csv文件批量导入角色
+func (*SysRoleController) ImportRoles(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(buserr.NewNoticeBusErr("请上传csv文件！"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	strict := req.Data.(*request.SysRoleImportReq).Strict
+	imported, errs, err := service.SysRole.ImportRoles(req.Context, file, strict)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := &response.SysRoleImportResp{Imported: imported, Errors: errs}
+	if strict && len(errs) > 0 {
+		response.FailWithResult(resp, "存在校验失败的数据，已取消导入！", c)
+	} else {
+		response.OkWithData(resp, c)
+	}
+}
+
+// ExportRoles 导出角色及其绑定的菜单为json文件
+func (*SysRoleController) ExportRoles(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	data, err := service.SysRole.ExportRoles(req.Context, req.Data.(*request.SysRoleExportReq).Ids)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=roles.json")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// ImportRolesJson 从导出的json文件导入角色
+func (*SysRoleController) ImportRolesJson(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(buserr.NewNoticeBusErr("请上传json文件！"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	opts := req.Data.(*request.SysRoleImportJsonReq)
+	if resp, err2 := service.SysRole.ImportRolesJson(req.Context, data, *opts); err2 != nil {
+		_ = c.Error(err2)
+	} else {
+		response.OkWithData(resp, c)
+	}
+}
+
 // AssignRoleMenus 分配角色菜单
 func (*SysRoleController) AssignRoleMenus(c *gin.Context) {
 
@@ -81,13 +303,196 @@ func (*SysRoleController) AssignRoleMenus(c *gin.Context) {
 
 }
 
-// AllSimpleRoles 获取角色列表（用户管理页面分配用户角色时展示使用）
+// GetRole 获取角色详情，支持 If-None-Match 条件请求
+func (*SysRoleController) GetRole(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	if res, err := service.SysRole.GetRole(req.Context, req.Data.(*request.QueryIdReq).Id); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithETag(response.NewETag(false, res.Id, res.Version, res.UpdatedAt.UnixNano()), res, c)
+	}
+}
+
+// GetRoleTree 获取角色树，支持 If-None-Match 条件请求
+func (*SysRoleController) GetRoleTree(c *gin.Context) {
+
+	if notModified, err := roleListNotModified(c); err != nil || notModified {
+		return
+	}
+
+	if res, err := service.SysRole.GetRoleTree(c.Request.Context()); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(res, c)
+	}
+}
+
+// GetEffectiveMenuIds 获取角色的有效菜单id（包含继承自父角色的菜单）
+func (*SysRoleController) GetEffectiveMenuIds(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	if res, err := service.SysRole.GetEffectiveMenuIds(req.Context, req.Data.(*request.QueryIdReq).Id); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(res, c)
+	}
+}
+
+// PageRoleUsers 角色下的用户列表
+func (*SysRoleController) PageRoleUsers(c *gin.Context) {
+
+	var req request.SysRoleUserPageReq
+
+	// 绑定参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	// 初始化默认值
+	req.InitDefaultValue()
+
+	if data, err := service.SysRole.PageRoleUsers(c.Request.Context(), &req); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(data, c)
+	}
+}
+
+// PageRolesWithUserCount 角色列表及每个角色的用户数量
+func (*SysRoleController) PageRolesWithUserCount(c *gin.Context) {
+
+	var req common.PageReq
+
+	// 绑定参数
+	if err := c.ShouldBindJSON(&req); err != nil && err.Error() != "EOF" {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	// 初始化默认值
+	req.InitDefaultValue()
+
+	if data, err := service.SysRole.PageRolesWithUserCount(c.Request.Context(), &req); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(data, c)
+	}
+}
+
+// CursorRoleUsers 角色下的用户列表（游标分页）
+func (*SysRoleController) CursorRoleUsers(c *gin.Context) {
+
+	var req request.SysRoleUserCursorReq
+
+	// 绑定参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	// 初始化默认值
+	req.InitDefaultValue()
+
+	if data, err := service.SysRole.CursorRoleUsers(c.Request.Context(), &req); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(data, c)
+	}
+}
+
+// ListRolesByUser 获取用户拥有的角色
+func (*SysRoleController) ListRolesByUser(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	if roles, err := service.SysRole.GetRolesByUser(req.Context, req.Data.(*request.QueryIdReq).Id); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(roles, c)
+	}
+}
+
+// SearchRoles 根据菜单的权限标识查询角色，mode=prefix 时按前缀匹配
+func (*SysRoleController) SearchRoles(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	searchReq := req.Data.(*request.SysRoleSearchReq)
+
+	if roles, err := service.SysRole.SearchRolesByPermissionCode(req.Context, searchReq.Perm, searchReq.Mode == "prefix"); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(roles, c)
+	}
+}
+
+// ListRolesByMenu 获取绑定了菜单的角色
+func (*SysRoleController) ListRolesByMenu(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+
+	if roles, err := service.SysRole.GetRolesByMenu(req.Context, req.Data.(*request.QueryIdReq).Id); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(roles, c)
+	}
+}
+
+// AddRoleUsers 为角色添加用户
+func (*SysRoleController) AddRoleUsers(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	usersReq := req.Data.(*request.SysRoleUsersReq)
+
+	if err := service.SysRole.AddUsersToRole(req.Context, usersReq.RoleId, usersReq.UserIds); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(true, c)
+	}
+}
+
+// RemoveRoleUsers 移除角色下的用户
+func (*SysRoleController) RemoveRoleUsers(c *gin.Context) {
+
+	_request, _ := c.Get(common.RequestKey)
+	req := _request.(*common.Request)
+	usersReq := req.Data.(*request.SysRoleUsersReq)
+
+	if err := service.SysRole.RemoveUsersFromRole(req.Context, usersReq.RoleId, usersReq.UserIds); err != nil {
+		_ = c.Error(err)
+	} else {
+		response.OkWithData(true, c)
+	}
+}
+
+// AllSimpleRoles 获取角色列表（用户管理页面分配用户角色时展示使用），支持 If-None-Match 条件请求
 func (*SysRoleController) AllSimpleRoles(c *gin.Context) {
 
-	if res, err := service.SysRole.AllSimpleRoles(); err != nil {
+	if notModified, err := roleListNotModified(c); err != nil || notModified {
+		return
+	}
+
+	if res, err := service.SysRole.AllSimpleRoles(c.Request.Context()); err != nil {
 		_ = c.Error(err)
 	} else {
 		response.OkWithData(res, c)
 	}
 
 }
+
+// roleListNotModified 根据角色数量和最近的修改时间生成弱 ETag，角色列表未变更时响应 304
+func roleListNotModified(c *gin.Context) (bool, error) {
+
+	count, updatedAt, err := service.SysRole.GetRoleListStamp(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return false, err
+	}
+	return response.NotModified(response.NewETag(true, count, updatedAt.UnixNano()), c), nil
+}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"gitee.com/nichanghao/gdmin/common"
 	"gitee.com/nichanghao/gdmin/common/buserr"
 	"gitee.com/nichanghao/gdmin/model"
@@ -19,7 +20,7 @@ func (*SysUserController) Login(c *gin.Context) {
 
 	// 绑定参数
 	if err := c.ShouldBindJSON(&req); err != nil {
-		_ = c.Error(err)
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
@@ -32,6 +33,22 @@ func (*SysUserController) Login(c *gin.Context) {
 
 }
 
+// RefreshToken 刷新token
+func (*SysUserController) RefreshToken(c *gin.Context) {
+
+	claims, err := common.USER_CTX.GetUserClaims(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if resp, err2 := service.SysUser.RefreshToken(claims.ID); err2 != nil {
+		_ = c.Error(err2)
+	} else {
+		response.OkWithData(resp, c)
+	}
+}
+
 // GetSelfUserInfo 获取当前用户信息
 func (*SysUserController) GetSelfUserInfo(c *gin.Context) {
 
@@ -54,13 +71,20 @@ func (*SysUserController) PageUsers(c *gin.Context) {
 
 	// 绑定参数
 	if err := c.ShouldBindJSON(&req); err != nil && err.Error() != "EOF" {
-		_ = c.Error(err)
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 	// 初始化默认值
 	req.InitDefaultValue()
 
-	if users, err := service.SysUser.PageUsers(&req); err != nil {
+	claims, err := common.USER_CTX.GetUserClaims(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	ctx := context.WithValue(c.Request.Context(), common.ClaimsKey, claims)
+
+	if users, err := service.SysUser.PageUsers(&common.Request{Data: &req, Context: ctx}); err != nil {
 		_ = c.Error(err)
 	} else {
 		response.OkWithData(users, c)
@@ -131,7 +155,7 @@ func (*SysUserController) UpdateStatus(c *gin.Context) {
 
 	// 绑定参数
 	if err := c.ShouldBindJSON(&req); err != nil {
-		_ = c.Error(err)
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
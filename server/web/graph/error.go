@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/common/i18n"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// ErrorPresenter 将业务错误转换为 GraphQL 错误，错误码放在 extensions.code 中，与 REST 接口的 code 一致
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	// 解析、校验请求等 gqlgen 产生的错误没有原始错误，原样返回
+	if gqlErr.Err == nil {
+		return gqlErr
+	}
+
+	// 数据库错误转换为业务错误，不向客户端暴露原始错误信息
+	err = common.TranslateDBError(gqlErr.Err)
+	gqlErr.Message = err.Error()
+
+	var validErr *buserr.ValidationError
+	if errors.As(err, &validErr) {
+		gqlErr.Extensions = map[string]any{"code": buserr.ErrIllegalParameter.Code, "fields": validErr.Fields}
+	} else if code, ok := buserr.CodeOf(err); ok {
+		gqlErr.Extensions = map[string]any{"code": code}
+	} else {
+		// 未知错误只记录日志，向客户端返回通用的错误信息
+		zap.L().Error("GraphQL resolver error: ", zap.Error(err))
+		gqlErr.Message, _ = i18n.Translate(i18n.LocaleFrom(ctx), i18n.InternalError)
+	}
+	return gqlErr
+}
+
+// RecoverFunc 解析器 panic 时记录日志，只向客户端返回通用的错误信息
+func RecoverFunc(_ context.Context, err any) error {
+	zap.L().Error("GraphQL resolver panic: ", zap.Any("error", err), zap.Stack("stack"))
+	return errors.New("服务器内部错误！")
+}
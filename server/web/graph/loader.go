@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/service"
+	"github.com/vikstrous/dataloadgen"
+	"time"
+)
+
+// 批量加载的等待时间，等待期间同一层级字段的查询会合并为一次
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// Loaders 请求级的数据加载器，批量加载角色列表中每个角色的用户和菜单，避免 N+1 查询
+type Loaders struct {
+	RoleUsers *dataloadgen.Loader[uint64, []model.SysUser]
+	RoleMenus *dataloadgen.Loader[uint64, []model.SysMenu]
+}
+
+// NewLoaders 创建数据加载器，加载器会缓存已加载的数据，每个请求需创建新的加载器
+func NewLoaders() *Loaders {
+	return &Loaders{
+		RoleUsers: dataloadgen.NewLoader(fetchRoleUsers, dataloadgen.WithWait(loaderWait)),
+		RoleMenus: dataloadgen.NewLoader(fetchRoleMenus, dataloadgen.WithWait(loaderWait)),
+	}
+}
+
+// WithLoaders 将数据加载器保存至上下文
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFromContext 获取上下文中的数据加载器，不存在时创建新的加载器
+func loadersFromContext(ctx context.Context) *Loaders {
+	if loaders, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return loaders
+	}
+	return NewLoaders()
+}
+
+func fetchRoleUsers(ctx context.Context, roleIds []uint64) ([][]model.SysUser, []error) {
+	users, err := service.SysRole.GetUsersByRoleIds(ctx, roleIds)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	res := make([][]model.SysUser, len(roleIds))
+	for i, roleId := range roleIds {
+		res[i] = users[roleId]
+	}
+	return res, nil
+}
+
+func fetchRoleMenus(ctx context.Context, roleIds []uint64) ([][]model.SysMenu, []error) {
+	menus, err := service.SysRole.GetMenusByRoleIds(ctx, roleIds)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	res := make([][]model.SysMenu, len(roleIds))
+	for i, roleId := range roleIds {
+		res[i] = menus[roleId]
+	}
+	return res, nil
+}
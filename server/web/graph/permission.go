@@ -0,0 +1,22 @@
+package graph
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/common/buserr"
+	"gitee.com/nichanghao/gdmin/service"
+)
+
+// checkPermission 校验当前用户是否拥有权限，权限标识与 REST 接口一致。
+// GraphQL 只有一个入口，无法通过 casbin 中间件按路由校验，由各解析器校验
+func checkPermission(ctx context.Context, permission string) error {
+	userId := common.USER_CTX.GetUserId(&ctx)
+	if userId == 0 {
+		return buserr.ErrPermissionDenied
+	}
+
+	if enforce, err := service.SysCasbin.Enforce(service.SysCasbin.GetCasbinUserStr(userId), permission); err != nil || !enforce {
+		return buserr.ErrPermissionDenied
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate --config gqlgen.yml
+
+// Resolver GraphQL 根解析器，数据读写均委托给角色服务
+type Resolver struct{}
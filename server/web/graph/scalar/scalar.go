@@ -0,0 +1,49 @@
+// Package scalar GraphQL 标量与模型字段类型之间的转换
+package scalar
+
+import (
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"math"
+	"strconv"
+)
+
+// MarshalUint64ID uint64 类型的主键按字符串输出，避免前端数字精度丢失
+func MarshalUint64ID(id uint64) graphql.Marshaler {
+	return graphql.MarshalID(strconv.FormatUint(id, 10))
+}
+
+// UnmarshalUint64ID 主键参数支持字符串和数字
+func UnmarshalUint64ID(v any) (uint64, error) {
+	return graphql.UnmarshalUint64(v)
+}
+
+func MarshalInt8(i int8) graphql.Marshaler {
+	return graphql.MarshalInt(int(i))
+}
+
+func UnmarshalInt8(v any) (int8, error) {
+	i, err := graphql.UnmarshalInt(v)
+	if err != nil {
+		return 0, err
+	}
+	if i < math.MinInt8 || i > math.MaxInt8 {
+		return 0, fmt.Errorf("%d overflows int8", i)
+	}
+	return int8(i), nil
+}
+
+func MarshalUint8(i uint8) graphql.Marshaler {
+	return graphql.MarshalInt(int(i))
+}
+
+func UnmarshalUint8(v any) (uint8, error) {
+	i, err := graphql.UnmarshalInt(v)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 || i > math.MaxUint8 {
+		return 0, fmt.Errorf("%d overflows uint8", i)
+	}
+	return uint8(i), nil
+}
@@ -0,0 +1,157 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.49
+
+import (
+	"context"
+	"errors"
+
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/service"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// CreateRole is the resolver for the createRole field.
+func (r *mutationResolver) CreateRole(ctx context.Context, input request.SysRoleAddReq) (*model.SysRole, error) {
+	if err := checkPermission(ctx, "sys:role:add"); err != nil {
+		return nil, err
+	}
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return nil, err
+	}
+
+	err := common.RunInTx(ctx, func(ctx context.Context) error {
+		return service.SysRole.AddRole(&common.Request{Data: &input, Context: ctx})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return service.SysRole.GetRoleByCode(ctx, input.Code)
+}
+
+// UpdateRole is the resolver for the updateRole field.
+func (r *mutationResolver) UpdateRole(ctx context.Context, input request.SysRoleEditReq) (*model.SysRole, error) {
+	if err := checkPermission(ctx, "sys:role:edit"); err != nil {
+		return nil, err
+	}
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return nil, err
+	}
+
+	err := common.RunInTx(ctx, func(ctx context.Context) error {
+		return service.SysRole.EditRole(&common.Request{Data: &input, Context: ctx})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return service.SysRole.GetById(ctx, input.Id)
+}
+
+// DeleteRole is the resolver for the deleteRole field.
+func (r *mutationResolver) DeleteRole(ctx context.Context, id uint64, force *bool) (bool, error) {
+	if err := checkPermission(ctx, "sys:role:delete"); err != nil {
+		return false, err
+	}
+
+	deleteReq := &request.SysRoleDeleteReq{Id: id, Force: force != nil && *force}
+	err := common.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := service.SysRole.DeleteRole(&common.Request{Data: deleteReq, Context: ctx})
+		return err
+	})
+	return err == nil, err
+}
+
+// Role is the resolver for the role field.
+func (r *queryResolver) Role(ctx context.Context, id uint64) (*model.SysRole, error) {
+	if err := checkPermission(ctx, "sys:role"); err != nil {
+		return nil, err
+	}
+
+	role, err := service.SysRole.GetById(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return role, err
+}
+
+// Roles is the resolver for the roles field.
+func (r *queryResolver) Roles(ctx context.Context, name *string, code *string, status *int, current *int, size *int) (*RolePage, error) {
+	if err := checkPermission(ctx, "sys:role"); err != nil {
+		return nil, err
+	}
+
+	var req request.SysRolePageReq
+	if name != nil {
+		req.Name = *name
+	}
+	if code != nil {
+		req.Code = *code
+	}
+	if status != nil {
+		req.Status = int8(*status)
+	}
+	if current != nil {
+		req.Current = *current
+	}
+	if size != nil {
+		req.Size = *size
+	}
+	req.InitDefaultValue()
+
+	data, err := service.SysRole.PageRoles(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &RolePage{Total: int(data.Total), Records: make([]model.SysRole, 0)}
+	if roles, ok := data.Records.([]*model.SysRole); ok {
+		for _, role := range roles {
+			res.Records = append(res.Records, *role)
+		}
+	}
+	return res, nil
+}
+
+// Users is the resolver for the users field.
+func (r *roleResolver) Users(ctx context.Context, obj *model.SysRole) ([]model.SysUser, error) {
+	if err := checkPermission(ctx, "sys:role"); err != nil {
+		return nil, err
+	}
+
+	users, err := loadersFromContext(ctx).RoleUsers.Load(ctx, obj.Id)
+	if users == nil {
+		users = make([]model.SysUser, 0)
+	}
+	return users, err
+}
+
+// Menus is the resolver for the menus field.
+func (r *roleResolver) Menus(ctx context.Context, obj *model.SysRole) ([]model.SysMenu, error) {
+	if err := checkPermission(ctx, "sys:role"); err != nil {
+		return nil, err
+	}
+
+	menus, err := loadersFromContext(ctx).RoleMenus.Load(ctx, obj.Id)
+	if menus == nil {
+		menus = make([]model.SysMenu, 0)
+	}
+	return menus, err
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Role returns RoleResolver implementation.
+func (r *Resolver) Role() RoleResolver { return &roleResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type roleResolver struct{ *Resolver }
@@ -0,0 +1,239 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/gin-gonic/gin"
+)
+
+// Operation 接口文档描述，请求参数和响应数据的结构由对应类型的 json、form、binding 及 gorm 标签生成
+type Operation struct {
+	Tag     string // 接口分组
+	Summary string // 接口说明
+	Query   any    // 查询参数，按 form 标签生成
+	Body    any    // json 请求体
+	Resp    any    // 响应中 data 的数据
+}
+
+var (
+	operations = make(map[string]Operation)
+	spec       []byte
+)
+
+// AddOperation 为处理函数添加接口文档，生成文档时只包含已注册路由且添加了文档的接口
+func AddOperation(fn gin.HandlerFunc, op Operation) {
+	funcName := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	operations[funcName] = op
+}
+
+// Init 根据已注册的路由生成 OpenAPI 文档，需在所有路由注册之后调用
+func Init(routes gin.RoutesInfo) error {
+
+	doc, err := Build(routes)
+	if err != nil {
+		return err
+	}
+
+	spec, err = json.Marshal(doc)
+	return err
+}
+
+// JSON 获取生成的 OpenAPI 文档
+func JSON() []byte {
+	return spec
+}
+
+// Build 根据路由生成 OpenAPI 3 文档，并校验文档是否符合规范
+func Build(routes gin.RoutesInfo) (*openapi3.T, error) {
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "gdmin", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: make(openapi3.Schemas),
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"bearerAuth": &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme()},
+			},
+		},
+	}
+	gen := openapi3gen.NewGenerator(
+		openapi3gen.SchemaCustomizer(customizeSchema),
+		openapi3gen.CreateComponentSchemas(openapi3gen.ExportComponentSchemasOptions{
+			ExportComponentSchemas: true,
+			ExportTopLevelSchema:   true,
+		}),
+	)
+
+	routes = append(gin.RoutesInfo(nil), routes...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	for _, route := range routes {
+		op, ok := operations[route.Handler]
+		if !ok {
+			continue
+		}
+
+		operation, err := buildOperation(gen, doc.Components.Schemas, route, op)
+		if err != nil {
+			return nil, err
+		}
+		path, _ := convertPath(route.Path)
+		doc.AddOperation(path, route.Method, operation)
+	}
+
+	// time.Time 没有导出字段，生成器只引用而不输出其组件定义
+	if _, ok := doc.Components.Schemas["Time"]; !ok {
+		doc.Components.Schemas["Time"] = openapi3.NewDateTimeSchema().NewRef()
+	}
+
+	// 生成器输出的组件引用没有关联组件定义，重新加载文档以解析引用后再校验
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = openapi3.NewLoader().LoadFromData(data); err != nil {
+		return nil, err
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// buildOperation 生成单个接口的文档
+func buildOperation(gen *openapi3gen.Generator, schemas openapi3.Schemas, route gin.RouteInfo, op Operation) (*openapi3.Operation, error) {
+
+	operation := &openapi3.Operation{
+		Tags:        []string{op.Tag},
+		Summary:     op.Summary,
+		OperationID: operationId(route.Handler),
+		Security:    &openapi3.SecurityRequirements{openapi3.NewSecurityRequirement().Authenticate("bearerAuth")},
+	}
+
+	// 1. 路径参数
+	_, pathParams := convertPath(route.Path)
+	for _, name := range pathParams {
+		schema := openapi3.NewInt64Schema().WithMin(1)
+		operation.AddParameter(openapi3.NewPathParameter(name).WithSchema(schema))
+	}
+
+	// 2. 查询参数
+	if op.Query != nil {
+		params, err := queryParameters(gen, schemas, reflect.TypeOf(op.Query))
+		if err != nil {
+			return nil, err
+		}
+		for _, param := range params {
+			operation.AddParameter(param)
+		}
+	}
+
+	// 3. 请求体
+	if op.Body != nil {
+		ref, err := gen.NewSchemaRefForValue(op.Body, schemas)
+		if err != nil {
+			return nil, err
+		}
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(ref),
+		}
+	}
+
+	// 4. 响应数据，统一包装为 {code, data, msg}
+	data := openapi3.NewSchemaRef("", &openapi3.Schema{})
+	if op.Resp != nil {
+		ref, err := gen.NewSchemaRefForValue(op.Resp, schemas)
+		if err != nil {
+			return nil, err
+		}
+		data = ref
+	}
+	result := openapi3.NewObjectSchema().
+		WithProperty("code", openapi3.NewIntegerSchema()).
+		WithPropertyRef("data", data).
+		WithProperty("msg", openapi3.NewStringSchema())
+	result.Required = []string{"code", "data", "msg"}
+
+	resp := openapi3.NewResponse().WithDescription("业务处理结果，code 为 200 时表示成功").WithJSONSchema(result)
+	operation.Responses = openapi3.NewResponses(openapi3.WithStatus(http.StatusOK, &openapi3.ResponseRef{Value: resp}))
+
+	return operation, nil
+}
+
+// queryParameters 根据 form 标签生成查询参数
+func queryParameters(gen *openapi3gen.Generator, schemas openapi3.Schemas, t reflect.Type) ([]*openapi3.Parameter, error) {
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var params []*openapi3.Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			embedded, err := queryParameters(gen, schemas, field.Type)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, embedded...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		ref, err := gen.NewSchemaRefForValue(reflect.Zero(field.Type).Interface(), schemas)
+		if err != nil {
+			return nil, err
+		}
+		if err = customizeSchema(name, field.Type, field.Tag, ref.Value); err != nil {
+			return nil, err
+		}
+
+		param := openapi3.NewQueryParameter(name).WithSchema(ref.Value)
+		param.Description = ref.Value.Description
+		param.Required = hasBindingRule(field.Tag, "required")
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// convertPath 将 gin 的路径参数 :id 转换为 OpenAPI 的 {id}
+func convertPath(path string) (string, []string) {
+
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationId 使用处理函数的方法名作为接口id，如 (*SysRoleController).PageRoles-fm 转换为 PageRoles
+func operationId(handler string) string {
+
+	name := strings.TrimSuffix(handler, "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
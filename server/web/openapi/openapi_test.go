@@ -0,0 +1,110 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gitee.com/nichanghao/gdmin/config"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/web/openapi"
+	"gitee.com/nichanghao/gdmin/web/router"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"os"
+	"slices"
+	"testing"
+)
+
+// oasSchemaUrl OpenAPI 3.0 规范的 JSON Schema，testdata 中为 https://spec.openapis.org/oas/3.0/schema/2021-09-28 的副本
+const oasSchemaUrl = "https://spec.openapis.org/oas/3.0/schema/2021-09-28"
+
+// testEngine 与 initialize.InitGin 注册相同的路由，接口文档由 router 包注册；
+// 使用空配置，中间件不启用限流等功能
+func testEngine(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	oldConfig := global.Config
+	global.Config = &config.Config{}
+	t.Cleanup(func() { global.Config = oldConfig })
+
+	engine := gin.New()
+	router.Base.InitRouter(engine.Group(""))
+	router.Self.InitRouter(engine.Group(""))
+	router.Private.InitRouter(engine.Group(""))
+	return engine
+}
+
+func TestBuild(t *testing.T) {
+	engine := testEngine(t)
+
+	doc, err := openapi.Build(engine.Routes())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	// 只包含添加了文档的接口，路径参数转换为 {id}
+	var operations int
+	for _, path := range doc.Paths.Map() {
+		operations += len(path.Operations())
+	}
+	if operations != 8 || doc.Paths.Find("/sys/role/tree") != nil {
+		t.Fatalf("paths = %v", doc.Paths.InMatchingOrder())
+	}
+	getRole := doc.Paths.Find("/sys/role/{id}").Get
+	if getRole == nil || getRole.OperationID != "GetRole" || getRole.Parameters.GetByInAndName(openapi3.ParameterInPath, "id") == nil {
+		t.Fatalf("GET /sys/role/{id} = %+v", getRole)
+	}
+	if patchRole := doc.Paths.Find("/sys/role/{id}").Patch; patchRole == nil || patchRole.OperationID != "PatchRole" {
+		t.Fatalf("PATCH /sys/role/{id} = %+v", patchRole)
+	}
+
+	// 查询参数按 form 标签生成，binding:"required" 的参数必填
+	deleteRole := doc.Paths.Find("/sys/role/delete").Delete
+	if param := deleteRole.Parameters.GetByInAndName(openapi3.ParameterInQuery, "id"); param == nil || !param.Required {
+		t.Fatalf("DELETE /sys/role/delete id parameter = %+v", param)
+	}
+	if param := deleteRole.Parameters.GetByInAndName(openapi3.ParameterInQuery, "dryRun"); param == nil || param.Required {
+		t.Fatalf("DELETE /sys/role/delete dryRun parameter = %+v", param)
+	}
+
+	// 请求体的必填字段
+	addRole := doc.Paths.Find("/sys/role/add").Post
+	body := addRole.RequestBody.Value.Content.Get("application/json").Schema.Value
+	if !slices.Contains(body.Required, "name") || !slices.Contains(body.Required, "code") {
+		t.Fatalf("POST /sys/role/add required = %v", body.Required)
+	}
+}
+
+// 对外提供的文档符合 OpenAPI 3.0 规范的 JSON Schema
+func TestJSONMatchesMetaSchema(t *testing.T) {
+	if err := openapi.Init(testEngine(t).Routes()); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	metaSchema, err := os.ReadFile("testdata/oas-3.0-schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft4
+	if err = compiler.AddResource(oasSchemaUrl, bytes.NewReader(metaSchema)); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := compiler.Compile(oasSchemaUrl)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(openapi.JSON()))
+	decoder.UseNumber()
+	var spec any
+	if err = decoder.Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+	if err = schema.Validate(spec); err != nil {
+		t.Fatalf("document does not match the OpenAPI 3.0 schema: %#v", err)
+	}
+}
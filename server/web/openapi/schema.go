@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// customizeSchema 根据字段标签补充约束：gorm 的 comment、size 及关联关系，binding 的 oneof、min、max 等校验规则，
+// 结构体本身根据字段的 binding:"required" 生成必填字段
+func customizeSchema(_ string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema.Required = requiredFields(t)
+	case reflect.Uint64:
+		// id 等字段实际不会超过 int64 的范围，避免输出超出 js 精度的最大值
+		schema.Format = "int64"
+		schema.Max = nil
+	}
+
+	// 1. gorm 标签
+	for _, setting := range strings.Split(tag.Get("gorm"), ";") {
+		key, value, _ := strings.Cut(setting, ":")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "comment":
+			schema.Description = value
+		case "size":
+			if size, err := strconv.ParseUint(value, 10, 64); err == nil && t.Kind() == reflect.String {
+				schema.MaxLength = &size
+			}
+		case "many2many":
+			// 关联数据通过单独的接口维护和查询，如角色下的用户
+			schema.ReadOnly = true
+		}
+	}
+
+	// 2. binding 标签
+	for _, rule := range strings.Split(tag.Get("binding"), ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "oneof":
+			if t.Kind() != reflect.String {
+				continue
+			}
+			for _, v := range strings.Fields(value) {
+				schema.Enum = append(schema.Enum, v)
+			}
+		case "min", "gte":
+			setMin(t, schema, value)
+		case "max", "lte":
+			setMax(t, schema, value)
+		}
+	}
+	return nil
+}
+
+// requiredFields 获取结构体中 binding:"required" 的字段，包含嵌入结构体的字段
+func requiredFields(t reflect.Type) []string {
+
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && jsonName == "" && field.Type.Kind() == reflect.Struct {
+			required = append(required, requiredFields(field.Type)...)
+			continue
+		}
+		if jsonName != "" && jsonName != "-" && hasBindingRule(field.Tag, "required") {
+			required = append(required, jsonName)
+		}
+	}
+	return required
+}
+
+// hasBindingRule 字段的 binding 标签是否包含指定规则
+func hasBindingRule(tag reflect.StructTag, rule string) bool {
+
+	for _, r := range strings.Split(tag.Get("binding"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// setMin 字符串和数组限制长度，数字限制取值
+func setMin(t reflect.Type, schema *openapi3.Schema, value string) {
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return
+	}
+	switch t.Kind() {
+	case reflect.String:
+		schema.MinLength = n
+	case reflect.Slice, reflect.Array:
+		schema.MinItems = n
+	default:
+		v := float64(n)
+		schema.Min = &v
+	}
+}
+
+// setMax 字符串和数组限制长度，数字限制取值
+func setMax(t reflect.Type, schema *openapi3.Schema, value string) {
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return
+	}
+	switch t.Kind() {
+	case reflect.String:
+		schema.MaxLength = &n
+	case reflect.Slice, reflect.Array:
+		schema.MaxItems = &n
+	default:
+		v := float64(n)
+		schema.Max = &v
+	}
+}
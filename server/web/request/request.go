@@ -18,12 +18,26 @@ type (
 )
 
 type (
-	SysRolePageReq       = system.SysRolePageReq
-	SysRoleAddReq        = system.SysRoleAddReq
-	SysRoleEditReq       = system.SysRoleEditReq
-	SysAssignRoleMenuReq = system.SysAssignRoleMenuReq
+	SysRolePageReq        = system.SysRolePageReq
+	SysRoleAddReq         = system.SysRoleAddReq
+	SysRoleEditReq        = system.SysRoleEditReq
+	SysRolePatchReq       = system.SysRolePatchReq
+	SysRoleDeleteReq      = system.SysRoleDeleteReq
+	SysRoleBatchDeleteReq = system.SysRoleBatchDeleteReq
+	SysRoleCloneReq       = system.SysRoleCloneReq
+	SysRoleSwapCodesReq   = system.SysRoleSwapCodesReq
+	SysRoleImportReq      = system.SysRoleImportReq
+	SysRoleExportReq      = system.SysRoleExportReq
+	SysRoleSearchReq      = system.SysRoleSearchReq
+	SysRoleImportJsonReq  = system.SysRoleImportJsonReq
+	SysAssignRoleMenuReq  = system.SysAssignRoleMenuReq
+	SysRoleUserPageReq    = system.SysRoleUserPageReq
+	SysRoleCursorReq      = system.SysRoleCursorReq
+	SysRoleUserCursorReq  = system.SysRoleUserCursorReq
+	SysRoleUsersReq       = system.SysRoleUsersReq
+	SysRoleRevisionReq    = system.SysRoleRevisionReq
 )
 
 type QueryIdReq struct {
-	Id uint64 `form:"id" binding:"required"`
+	Id uint64 `form:"id" uri:"id" binding:"required"`
 }
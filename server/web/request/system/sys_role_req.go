@@ -2,28 +2,115 @@ package system
 
 import (
 	"gitee.com/nichanghao/gdmin/common"
+	"time"
 )
 
 type SysRolePageReq struct {
-	Name           string `json:"name"`   // 名称查询
-	Code           string `json:"code"`   // code查询
-	Status         int8   `json:"status"` // 状态(1:启用 2:禁用)
+	Name           string `json:"name"`                                                     // 名称查询
+	Code           string `json:"code"`                                                     // code查询
+	Status         int8   `json:"status"`                                                   // 状态(1:启用 2:禁用)
+	OrderBy        string `json:"orderBy" binding:"omitempty,oneof=id name code createdAt"` // 排序字段，默认id
+	OrderDir       string `json:"orderDir" binding:"omitempty,oneof=asc desc"`              // 排序方向，默认desc
 	common.PageReq        // 分页数据
 }
 
 type SysRoleAddReq struct {
-	Name   string `json:"name" binding:"required"` // 名称
-	Code   string `json:"code" binding:"required"` // code
-	Status int8   `json:"status"`                  // 状态(1:启用 2:禁用)
-	Desc   string `json:"desc"`                    // 描述
+	Name      string   `json:"name" binding:"required"`                   // 名称
+	Code      string   `json:"code" binding:"required"`                   // code
+	Status    int8     `json:"status"`                                    // 状态(1:启用 2:禁用)
+	Desc      string   `json:"desc"`                                      // 描述
+	DataScope int8     `json:"dataScope" binding:"omitempty,gte=1,lte=5"` // 数据权限范围(1:全部,2:本部门及以下,3:本部门,4:仅本人,5:自定义)
+	DeptIds   []uint64 `json:"deptIds"`                                   // 自定义数据权限的部门id集合
+	ParentId  uint64   `json:"parentId"`                                  // 父角色id，0表示无父角色
 }
 
 type SysRoleEditReq struct {
-	Id uint64 `json:"id" binding:"required"` // ID
+	Id      uint64 `json:"id" binding:"required"` // ID
+	Version int    `json:"version"`               // 版本号，用于乐观锁校验
 	SysRoleAddReq
 }
 
+// SysRolePatchReq 角色局部修改，未传的字段保持不变，传空字符串等零值时修改为零值
+type SysRolePatchReq struct {
+	Id        uint64    `uri:"id" json:"-" binding:"required"`             // ID
+	Version   *int      `json:"version"`                                   // 版本号，传入时进行乐观锁校验
+	Name      *string   `json:"name"`                                      // 名称，不能为空字符串
+	Code      *string   `json:"code"`                                      // code
+	Status    *uint8    `json:"status"`                                    // 状态(1:启用 2:禁用)
+	Desc      *string   `json:"desc"`                                      // 描述
+	DataScope *int8     `json:"dataScope" binding:"omitempty,gte=1,lte=5"` // 数据权限范围(1:全部,2:本部门及以下,3:本部门,4:仅本人,5:自定义)
+	DeptIds   *[]uint64 `json:"deptIds"`                                   // 自定义数据权限的部门id集合
+	ParentId  *uint64   `json:"parentId"`                                  // 父角色id，0表示无父角色
+}
+
+type SysRoleDeleteReq struct {
+	Id     uint64 `form:"id" binding:"required"` // ID
+	Force  bool   `form:"force"`                 // 角色已分配给用户时是否强制删除
+	DryRun bool   `form:"dryRun"`                // 是否只预览删除的影响，不修改数据
+}
+
+type SysRoleCloneReq struct {
+	Id   uint64 `json:"id" binding:"required"`   // 被复制的角色id
+	Name string `json:"name" binding:"required"` // 新角色名称
+	Code string `json:"code" binding:"required"` // 新角色编码
+}
+
+type SysRoleSwapCodesReq struct {
+	IdA uint64 `json:"idA" binding:"required"` // 交换编码的角色id
+	IdB uint64 `json:"idB" binding:"required"` // 交换编码的另一个角色id
+}
+
+type SysRoleBatchDeleteReq struct {
+	Ids   []uint64 `json:"ids" binding:"required,min=1"` // 角色id集合
+	Force bool     `json:"force"`                        // 角色已分配给用户时是否强制删除
+}
+
 type SysAssignRoleMenuReq struct {
 	RoleId  uint64   `json:"roleId" binding:"required"`  // 角色id
 	MenuIds []uint64 `json:"menuIds" binding:"required"` // 菜单id集合
 }
+
+type SysRoleUserPageReq struct {
+	RoleId         uint64 `json:"roleId" binding:"required"` // 角色id
+	common.PageReq        // 分页数据
+}
+
+type SysRoleCursorReq struct {
+	Name             string `json:"name"`   // 名称查询
+	Code             string `json:"code"`   // code查询
+	Status           int8   `json:"status"` // 状态(1:启用 2:禁用)
+	common.CursorReq        // 游标分页数据
+}
+
+type SysRoleUserCursorReq struct {
+	RoleId           uint64 `json:"roleId" binding:"required"` // 角色id
+	common.CursorReq        // 游标分页数据
+}
+
+type SysRoleUsersReq struct {
+	RoleId  uint64   `json:"roleId" binding:"required"`        // 角色id
+	UserIds []uint64 `json:"userIds" binding:"required,min=1"` // 用户id集合
+}
+
+type SysRoleImportReq struct {
+	Strict bool `form:"strict"` // 存在校验失败的行时是否取消整个导入
+}
+
+type SysRoleExportReq struct {
+	Ids []uint64 `form:"ids"` // 导出的角色id集合，为空时导出全部角色
+}
+
+type SysRoleSearchReq struct {
+	Perm string `form:"perm" binding:"required"`                     // 菜单的权限标识，前缀匹配时可以 * 结尾，如 sys:user:*
+	Mode string `form:"mode" binding:"omitempty,oneof=exact prefix"` // 匹配方式，exact：精确匹配（默认），prefix：前缀匹配
+}
+
+type SysRoleImportJsonReq struct {
+	OnConflict string `form:"onConflict" binding:"omitempty,oneof=skip overwrite error"` // 角色编码已存在时的处理方式，默认error
+}
+
+type SysRoleRevisionReq struct {
+	Id       uint64    `form:"id" binding:"required"` // 角色id
+	Revision uint64    `form:"revision"`              // 修订号，为0时查询 at 时间的角色数据
+	At       time.Time `form:"at"`                    // 查询的时间，RFC3339 格式，如 2024-08-01T10:00:00+08:00
+}
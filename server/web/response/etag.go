@@ -0,0 +1,60 @@
+package response
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewETag 根据数据的变更标识生成 ETag，weak 为 true 时生成弱校验的 ETag
+func NewETag(weak bool, parts ...interface{}) string {
+
+	h := fnv.New64a()
+	for _, part := range parts {
+		_, _ = fmt.Fprintf(h, "%v|", part)
+	}
+
+	etag := fmt.Sprintf(`"%x"`, h.Sum64())
+	if weak {
+		etag = "W/" + etag
+	}
+	return etag
+}
+
+// NotModified 设置响应头 ETag，请求头 If-None-Match 与 etag 匹配时响应 304 并返回 true，调用方不需要再返回数据
+func NotModified(etag string, c *gin.Context) bool {
+
+	c.Header("ETag", etag)
+	if !etagMatch(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// OkWithETag 返回带 ETag 的数据，数据未变更时响应 304
+func OkWithETag(etag string, data interface{}, c *gin.Context) {
+
+	if !NotModified(etag, c) {
+		OkWithData(data, c)
+	}
+}
+
+// etagMatch If-None-Match 使用弱比较，忽略 W/ 前缀
+func etagMatch(ifNoneMatch, etag string) bool {
+
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -16,4 +16,30 @@ type (
 	SysRoutesResp = system.SysRoutesResp
 
 	SysPermissionRoutersResp = system.SysPermissionRoutersResp
+
+	SysUserPermissionsResp = system.SysUserPermissionsResp
+)
+
+type (
+	RoleNode = system.RoleNode
+
+	SysRoleDetailResp = system.SysRoleDetailResp
+
+	RoleWithCount = system.RoleWithCount
+
+	SysSimpleRoleResp = system.SysSimpleRoleResp
+
+	RowError = system.RowError
+
+	SysRoleImportResp = system.SysRoleImportResp
+
+	SysRoleJsonImportResp = system.SysRoleJsonImportResp
+
+	RoleDeleteFailure = system.RoleDeleteFailure
+
+	RoleDeleteSummary = system.RoleDeleteSummary
+
+	SysRoleDeleteResp = system.SysRoleDeleteResp
+
+	RepairReport = system.RepairReport
 )
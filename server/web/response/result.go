@@ -53,6 +53,25 @@ func FailWithResult(data interface{}, message string, c *gin.Context) {
 	Result(http.StatusBadRequest, ERROR, data, message, c)
 }
 
+func FailWithValidationErr(validErr *error2.ValidationError, c *gin.Context) {
+	Result(http.StatusBadRequest, ERROR, validErr.Fields, validErr.Error(), c)
+}
+
 func FailWithBusErr(busErr *error2.BusinessError, c *gin.Context) {
 	Result(http.StatusOK, busErr.Code, nil, busErr.Error(), c)
 }
+
+// FailWithCode 业务异常响应，http状态码为200，由code码区分具体的业务错误
+func FailWithCode(code int, message string, c *gin.Context) {
+	Result(http.StatusOK, code, nil, message, c)
+}
+
+// FailWithInternalErr 未知错误响应，http状态码为500
+func FailWithInternalErr(message string, c *gin.Context) {
+	Result(http.StatusInternalServerError, http.StatusInternalServerError, nil, message, c)
+}
+
+// FailWithPanic 未处理的异常响应
+func FailWithPanic(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusInternalServerError, R{http.StatusInternalServerError, nil, "服务器内部错误！"})
+}
@@ -0,0 +1,7 @@
+package system
+
+// ReadinessResp 就绪检查结果
+type ReadinessResp struct {
+	Ready  bool              `json:"ready"`            // 是否就绪
+	Failed map[string]string `json:"failed,omitempty"` // 检查失败的依赖及原因
+}
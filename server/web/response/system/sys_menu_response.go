@@ -29,3 +29,9 @@ type SysPermissionRoutersResp struct {
 	Routes      []*SysRoutesResp `json:"routes"`      // 路由列表
 	Home        string           `json:"home"`        // 首页路由，用户登录后默认跳转的路由
 }
+
+// SysUserPermissionsResp 用户拥有的权限，前端用于控制页面和按钮的展示
+type SysUserPermissionsResp struct {
+	Routes      []string `json:"routes"`      // 目录和菜单的路由名称
+	Permissions []string `json:"permissions"` // 菜单和按钮的权限标识
+}
@@ -0,0 +1,101 @@
+package system
+
+import "gitee.com/nichanghao/gdmin/model"
+
+// SysRoleDetailResp 角色详情
+type SysRoleDetailResp struct {
+	*model.SysRole
+	MenuIds []uint64 `json:"menuIds"` // 角色直接绑定的菜单id，不包含继承自父角色的菜单
+}
+
+// RoleWithCount 角色及其关联的用户数量
+type RoleWithCount struct {
+	model.SysRole
+	UserCount int64 `json:"userCount"` // 角色关联的用户数量，不包含已删除的用户
+}
+
+// SysSimpleRoleResp 角色下拉选项
+type SysSimpleRoleResp struct {
+	Id   uint64 `json:"id"`   // 角色id
+	Name string `json:"name"` // 角色名称
+	Code string `json:"code"` // 角色编码
+}
+
+// RoleNode 角色树节点
+type RoleNode struct {
+	Id       uint64      `json:"id"`                // 角色id
+	Name     string      `json:"name"`              // 角色名称
+	Code     string      `json:"code"`              // 角色编码
+	Status   uint8       `json:"status"`            // 状态(1:启用 2:禁用)
+	ParentId uint64      `json:"parentId"`          // 父角色id
+	Children []*RoleNode `gorm:"-" json:"children"` // 子角色列表
+}
+
+// RowError 导入数据时校验失败的行
+type RowError struct {
+	Row     int    `json:"row"`     // 行号
+	Message string `json:"message"` // 失败原因
+}
+
+// SysRoleImportResp 角色导入结果
+type SysRoleImportResp struct {
+	Imported int        `json:"imported"` // 导入成功的数量
+	Errors   []RowError `json:"errors"`   // 校验失败的行
+}
+
+// RoleDeleteFailure 批量删除时删除失败的角色
+type RoleDeleteFailure struct {
+	Id      uint64 `json:"id"`      // 角色id
+	Message string `json:"message"` // 失败原因
+}
+
+// RoleDeleteSummary 删除的角色及解除的关联数量
+type RoleDeleteSummary struct {
+	Id           uint64 `json:"id"`           // 角色id
+	Name         string `json:"name"`         // 角色名称
+	Code         string `json:"code"`         // 角色编码
+	UserBindings int    `json:"userBindings"` // 解除关联的用户数量，仅强制删除时不为0
+	MenuBindings int64  `json:"menuBindings"` // 移除权限的菜单数量，菜单关联保留用于恢复角色
+}
+
+// SysRoleDeleteResp 角色删除结果，预览时为将要删除的角色及影响的数据，不会修改任何数据
+type SysRoleDeleteResp struct {
+	DryRun       bool                `json:"dryRun"`       // 是否为预览
+	Deleted      int                 `json:"deleted"`      // 删除成功的数量
+	UserBindings int                 `json:"userBindings"` // 解除关联的用户总数
+	MenuBindings int64               `json:"menuBindings"` // 移除权限的菜单总数
+	Roles        []RoleDeleteSummary `json:"roles"`        // 删除的角色
+	Failed       []RoleDeleteFailure `json:"failed"`       // 删除失败的角色
+}
+
+// Add 记录删除的角色
+func (resp *SysRoleDeleteResp) Add(summary RoleDeleteSummary) {
+	resp.Deleted++
+	resp.UserBindings += summary.UserBindings
+	resp.MenuBindings += summary.MenuBindings
+	resp.Roles = append(resp.Roles, summary)
+}
+
+// AddFailed 记录预览时删除会失败的角色及原因，统计影响的数据但不计入删除成功的数量
+func (resp *SysRoleDeleteResp) AddFailed(summary RoleDeleteSummary, message string) {
+	resp.UserBindings += summary.UserBindings
+	resp.MenuBindings += summary.MenuBindings
+	resp.Roles = append(resp.Roles, summary)
+	resp.Failed = append(resp.Failed, RoleDeleteFailure{Id: summary.Id, Message: message})
+}
+
+// SysRoleJsonImportResp 角色json导入结果
+type SysRoleJsonImportResp struct {
+	Created int      `json:"created"` // 新增的角色数量
+	Updated int      `json:"updated"` // 覆盖的角色数量
+	Skipped []string `json:"skipped"` // 因编码已存在而跳过的角色编码
+}
+
+// RepairReport 用户角色关联数据的修复结果，预览时只统计不删除
+type RepairReport struct {
+	DryRun      bool `json:"dryRun"`      // 是否为预览
+	Scanned     int  `json:"scanned"`     // 扫描的关联行数量
+	MissingRole int  `json:"missingRole"` // 角色不存在或已删除的关联行数量
+	MissingUser int  `json:"missingUser"` // 用户不存在或已删除的关联行数量
+	Deleted     int  `json:"deleted"`     // 删除的关联行数量
+}
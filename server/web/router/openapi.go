@@ -0,0 +1,51 @@
+package router
+
+import (
+	"gitee.com/nichanghao/gdmin/model"
+	"gitee.com/nichanghao/gdmin/web/controller"
+	"gitee.com/nichanghao/gdmin/web/openapi"
+	"gitee.com/nichanghao/gdmin/web/request"
+	"gitee.com/nichanghao/gdmin/web/response"
+)
+
+// rolePageResp 角色分页数据，仅用于生成接口文档
+type rolePageResp struct {
+	Total   int64           `json:"total"`
+	Records []model.SysRole `json:"records"`
+	Current int             `json:"current"`
+	Size    int             `json:"size"`
+}
+
+// roleCursorResp 角色游标分页数据，仅用于生成接口文档
+type roleCursorResp struct {
+	Records    []model.SysRole `json:"records"`
+	NextCursor string          `json:"nextCursor"`
+}
+
+// roleBatchDeleteQuery 批量删除角色的查询参数，仅用于生成接口文档
+type roleBatchDeleteQuery struct {
+	DryRun bool `form:"dryRun"` // 是否只预览删除的影响，不修改数据
+}
+
+func init() {
+	// 角色接口文档
+	{
+		const tag = "角色管理"
+		openapi.AddOperation(controller.SysRole.PageRoles, openapi.Operation{
+			Tag: tag, Summary: "分页查询角色", Body: request.SysRolePageReq{}, Resp: rolePageResp{}})
+		openapi.AddOperation(controller.SysRole.CursorRoles, openapi.Operation{
+			Tag: tag, Summary: "游标分页查询角色", Body: request.SysRoleCursorReq{}, Resp: roleCursorResp{}})
+		openapi.AddOperation(controller.SysRole.GetRole, openapi.Operation{
+			Tag: tag, Summary: "角色详情", Resp: response.SysRoleDetailResp{}})
+		openapi.AddOperation(controller.SysRole.AddRole, openapi.Operation{
+			Tag: tag, Summary: "创建角色", Body: request.SysRoleAddReq{}, Resp: true})
+		openapi.AddOperation(controller.SysRole.EditRole, openapi.Operation{
+			Tag: tag, Summary: "修改角色", Body: request.SysRoleEditReq{}, Resp: true})
+		openapi.AddOperation(controller.SysRole.PatchRole, openapi.Operation{
+			Tag: tag, Summary: "局部修改角色", Body: request.SysRolePatchReq{}, Resp: true})
+		openapi.AddOperation(controller.SysRole.DeleteRole, openapi.Operation{
+			Tag: tag, Summary: "删除角色", Query: request.SysRoleDeleteReq{}, Resp: response.SysRoleDeleteResp{}})
+		openapi.AddOperation(controller.SysRole.DeleteRoles, openapi.Operation{
+			Tag: tag, Summary: "批量删除角色", Query: roleBatchDeleteQuery{}, Body: request.SysRoleBatchDeleteReq{}, Resp: response.SysRoleDeleteResp{}})
+	}
+}
@@ -1,8 +1,10 @@
 package system
 
 import (
+	"gitee.com/nichanghao/gdmin/middleware"
 	"gitee.com/nichanghao/gdmin/web/controller"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
 )
 
@@ -15,7 +17,24 @@ func (*BaseRouter) InitRouter(group *gin.RouterGroup) {
 		c.JSON(http.StatusOK, "ok")
 	})
 
+	// 存活检查和就绪检查，用于k8s探针
+	group.GET("/healthz", controller.SysHealth.Healthz)
+	group.GET("/readyz", controller.SysHealth.Readyz)
+
+	// Prometheus 监控指标
+	group.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// OpenAPI 接口文档及 Swagger UI
+	group.GET("/openapi.json", controller.SysDoc.OpenAPI)
+	group.GET("/docs", controller.SysDoc.SwaggerUI)
+
+	// 登录及刷新token按客户端ip限流，健康检查不限流
+	rateLimitHandler := middleware.RateLimitHandler("base")
+
 	// 登录
-	group.POST("/login", controller.SysUser.Login)
+	group.POST("/login", rateLimitHandler, controller.SysUser.Login)
+
+	// 刷新token，用户角色变更后可使用旧token换取新token
+	group.POST("/refresh-token", rateLimitHandler, middleware.JwtRefreshAuthHandler(), controller.SysUser.RefreshToken)
 
 }
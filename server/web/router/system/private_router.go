@@ -26,6 +26,8 @@ func (*PrivateRouter) InitRouter(group *gin.RouterGroup) {
 		sysUserGroup.PUT("assign-roles",
 			middleware.RequestContextHandler(&request.SysUserAssignRoleReq{}), controller.SysUser.AssignRoles)
 		sysUserGroup.PUT("update-status", controller.SysUser.UpdateStatus)
+		sysUserGroup.GET(":id/roles",
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeUri), controller.SysRole.ListRolesByUser)
 	}
 
 	// 菜单相关路由
@@ -37,21 +39,74 @@ func (*PrivateRouter) InitRouter(group *gin.RouterGroup) {
 		sysMenuGroup.PUT("edit",
 			middleware.RequestContextHandler(&request.SysMenuUpdateReq{}), controller.SysMenu.EditMenu)
 		sysMenuGroup.DELETE("delete",
-			middleware.RequestContextHandler(&request.SysMenuUpdateReq{}, common.BindModeQuery), controller.SysMenu.DeleteMenu)
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeQuery), controller.SysMenu.DeleteMenu)
+		sysMenuGroup.GET(":id/roles",
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeUri), controller.SysRole.ListRolesByMenu)
 	}
 
 	// 角色相关路由
 	sysRoleGroup := group.Group("/sys/role")
 	{
 		sysRoleGroup.POST("page", controller.SysRole.PageRoles)
-		sysRoleGroup.POST("add",
-			middleware.RequestContextHandler(&request.SysRoleAddReq{}), controller.SysRole.AddRole)
-		sysRoleGroup.PUT("edit",
-			middleware.RequestContextHandler(&request.SysRoleEditReq{}), controller.SysRole.EditRole)
-		sysRoleGroup.DELETE("delete",
-			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeQuery), controller.SysRole.DeleteRole)
+		sysRoleGroup.POST("cursor", controller.SysRole.CursorRoles)
 		sysRoleGroup.PUT("assign-menus",
 			middleware.RequestContextHandler(&request.SysAssignRoleMenuReq{}), controller.SysRole.AssignRoleMenus)
+		sysRoleGroup.POST("recycle", controller.SysRole.PageDeletedRoles)
+		// 分批在各自的事务中修复，不使用请求级事务
+		sysRoleGroup.POST("repair-joins", controller.SysRole.RepairRoleJoins)
+		sysRoleGroup.GET("export",
+			middleware.RequestContextHandler(&request.SysRoleExportReq{}, common.BindModeQuery), controller.SysRole.ExportRoles)
+		sysRoleGroup.GET("tree", controller.SysRole.GetRoleTree)
+		sysRoleGroup.GET("search",
+			middleware.RequestContextHandler(&request.SysRoleSearchReq{}, common.BindModeQuery), controller.SysRole.SearchRoles)
+		sysRoleGroup.GET(":id",
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeUri), controller.SysRole.GetRole)
+		sysRoleGroup.GET("effective-menu-ids",
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeQuery), controller.SysRole.GetEffectiveMenuIds)
+		sysRoleGroup.POST("users", controller.SysRole.PageRoleUsers)
+		sysRoleGroup.POST("users-cursor", controller.SysRole.CursorRoleUsers)
+		sysRoleGroup.POST("user-counts", controller.SysRole.PageRolesWithUserCount)
+		sysRoleGroup.PUT("add-users",
+			middleware.RequestContextHandler(&request.SysRoleUsersReq{}), controller.SysRole.AddRoleUsers)
+		sysRoleGroup.PUT("remove-users",
+			middleware.RequestContextHandler(&request.SysRoleUsersReq{}), controller.SysRole.RemoveRoleUsers)
+	}
+
+	// 新增角色支持幂等键，幂等处理在事务之外，只缓存事务提交后的响应
+	group.POST("/sys/role/add", middleware.IdempotencyHandler(), middleware.TransactionHandler(),
+		middleware.RequestContextHandler(&request.SysRoleAddReq{}), controller.SysRole.AddRole)
+
+	// 角色写操作路由，角色数据与操作日志在同一个请求级事务中写入
+	sysRoleTxGroup := group.Group("/sys/role", middleware.TransactionHandler())
+	{
+		sysRoleTxGroup.POST("clone",
+			middleware.RequestContextHandler(&request.SysRoleCloneReq{}), controller.SysRole.CloneRole)
+		sysRoleTxGroup.PUT("edit",
+			middleware.RequestContextHandler(&request.SysRoleEditReq{}), controller.SysRole.EditRole)
+		sysRoleTxGroup.PATCH(":id",
+			middleware.RequestContextHandler(&request.SysRolePatchReq{}, common.BindModeUri), controller.SysRole.PatchRole)
+		sysRoleTxGroup.PUT("swap-codes",
+			middleware.RequestContextHandler(&request.SysRoleSwapCodesReq{}), controller.SysRole.SwapRoleCodes)
+		sysRoleTxGroup.DELETE("delete",
+			middleware.RequestContextHandler(&request.SysRoleDeleteReq{}, common.BindModeQuery), controller.SysRole.DeleteRole)
+		sysRoleTxGroup.DELETE("batch-delete",
+			middleware.RequestContextHandler(&request.SysRoleBatchDeleteReq{}), controller.SysRole.DeleteRoles)
+		sysRoleTxGroup.PUT("restore",
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeQuery), controller.SysRole.RestoreRole)
+		sysRoleTxGroup.POST("import",
+			middleware.RequestContextHandler(&request.SysRoleImportReq{}, common.BindModeQuery), controller.SysRole.ImportRoles)
+		sysRoleTxGroup.POST("import-json",
+			middleware.RequestContextHandler(&request.SysRoleImportJsonReq{}, common.BindModeQuery), controller.SysRole.ImportRolesJson)
+	}
 
+	// 操作日志相关路由
+	sysAuditGroup := group.Group("/sys/audit")
+	{
+		sysAuditGroup.GET("role",
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeQuery), controller.SysAudit.ListRoleLogs)
+		sysAuditGroup.GET("role-revisions",
+			middleware.RequestContextHandler(&request.QueryIdReq{}, common.BindModeQuery), controller.SysAudit.ListRoleRevisions)
+		sysAuditGroup.GET("role-revision",
+			middleware.RequestContextHandler(&request.SysRoleRevisionReq{}, common.BindModeQuery), controller.SysAudit.GetRoleRevision)
 	}
 }
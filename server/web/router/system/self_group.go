@@ -27,10 +27,17 @@ func (*SelfRouter) InitRouter(group *gin.RouterGroup) {
 		sysMenuGroup.GET("/self/permission-routers", controller.SysMenu.GetSelfPermissionRouters)
 	}
 
+	// 当前用户拥有的权限，前端用于控制按钮的展示
+	group.GET("/auth/permissions", controller.SysMenu.GetSelfPermissions)
+
 	// 角色相关路由
 	sysRoleGroup := group.Group("/sys/role")
 	{
 		sysRoleGroup.GET("/all-simple-roles", controller.SysRole.AllSimpleRoles)
+		sysRoleGroup.GET("/options", controller.SysRole.AllSimpleRoles)
 	}
 
+	// GraphQL 接口，只有一个入口，权限由解析器校验
+	group.POST("/graphql", controller.SysGraphql.Query)
+
 }
@@ -1,15 +1,85 @@
 package web
 
 import (
+	"context"
+	"errors"
+	"gitee.com/nichanghao/gdmin/event"
 	"gitee.com/nichanghao/gdmin/global"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// StartServer 启动web服务，收到停止信号后优雅停机
 func StartServer(engine *gin.Engine) {
 
-	err := engine.Run(global.Config.Gin.Address)
-	if err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: global.Config.Gin.Address, Handler: engine}
+	if err := Serve(ctx, server, time.Duration(global.Config.Gin.ShutdownTimeout)*time.Second); err != nil {
 		log.Fatalf("gin run error: %v", err)
 	}
+
+	closeResources()
+}
+
+// Serve 启动服务直到上下文取消，之后停止接收新连接，并等待进行中的请求在超时时间内完成，
+// 请求级事务在处理函数返回前提交，因此等待请求完成即可保证事务不会被中断。
+// 请求完成后继续在剩余的超时时间内等待事件订阅者处理完成（如 webhook 重试），之后才能关闭数据库等资源
+func Serve(ctx context.Context, server *http.Server, shutdownTimeout time.Duration) error {
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	zap.L().Info("收到停止信号，等待进行中的请求完成", zap.Duration("timeout", shutdownTimeout))
+	shutdownCtx := context.Background()
+	if shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, shutdownTimeout)
+		defer cancel()
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// 超时后强制关闭剩余的连接，未完成的事务由数据库回滚
+		zap.L().Warn("等待请求完成超时，强制关闭连接", zap.Error(err))
+		_ = server.Close()
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	if err := event.Bus.Close(shutdownCtx); err != nil {
+		zap.L().Warn("等待事件处理完成超时，取消未完成的事件处理", zap.Error(err))
+	}
+	return nil
+}
+
+// closeResources 所有请求完成后关闭数据库连接池及redis客户端
+func closeResources() {
+
+	if db, err := global.GormDB.DB(); err == nil {
+		if err = db.Close(); err != nil {
+			zap.L().Error("关闭数据库连接池失败：", zap.Error(err))
+		}
+	}
+	if global.RedisCli != nil {
+		if err := global.RedisCli.Close(); err != nil {
+			zap.L().Error("关闭redis客户端失败：", zap.Error(err))
+		}
+	}
+	zap.L().Info("服务已停止")
+	_ = zap.L().Sync()
 }
@@ -0,0 +1,125 @@
+package web
+
+import (
+	"context"
+	"gitee.com/nichanghao/gdmin/common"
+	"gitee.com/nichanghao/gdmin/event"
+	"gitee.com/nichanghao/gdmin/global"
+	"gitee.com/nichanghao/gdmin/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type shutdownItem struct {
+	Id   uint64 `gorm:"primarykey"`
+	Name string
+}
+
+type shutdownEvent struct{}
+
+func (shutdownEvent) Topic() string {
+	return "test.shutdown"
+}
+
+// 停机时进行中的请求完成并提交事务，请求中发布的事件处理完成后 Serve 才返回
+func TestServeDrainsRequestsAndEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "shutdown.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&shutdownItem{}); err != nil {
+		t.Fatal(err)
+	}
+	oldDB, oldBus := global.GormDB, event.Bus
+	global.GormDB, event.Bus = db, event.NewEventBus()
+	t.Cleanup(func() { global.GormDB, event.Bus = oldDB, oldBus })
+
+	var handled atomic.Bool
+	event.Bus.Subscribe(event.SubscriberFunc(func(ctx context.Context, e event.Event) error {
+		time.Sleep(100 * time.Millisecond)
+		handled.Store(true)
+		return nil
+	}))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	engine := gin.New()
+	engine.POST("/items", middleware.TransactionHandler(), func(c *gin.Context) {
+		close(started)
+		<-release
+		ctx := c.Request.Context()
+		if err := common.DBFromContext(ctx).Create(&shutdownItem{Name: "item"}).Error; err != nil {
+			_ = c.Error(err)
+			return
+		}
+		event.Bus.Publish(ctx, shutdownEvent{})
+		c.Status(http.StatusOK)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, &http.Server{Addr: addr, Handler: engine}, 5*time.Second)
+	}()
+
+	status := make(chan int, 1)
+	go func() {
+		// 等待服务启动
+		for i := 0; i < 100; i++ {
+			resp, err := http.Post("http://"+addr+"/items", "application/json", nil)
+			if err == nil {
+				_ = resp.Body.Close()
+				status <- resp.StatusCode
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		status <- 0
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("request not started")
+	}
+	stop()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case err = <-served:
+		if err != nil {
+			t.Fatalf("Serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+	if !handled.Load() {
+		t.Fatal("Serve returned before the event subscriber finished")
+	}
+	if code := <-status; code != http.StatusOK {
+		t.Fatalf("in-flight request status = %d, want 200", code)
+	}
+	var count int64
+	db.Model(&shutdownItem{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("committed rows = %d, want 1", count)
+	}
+}
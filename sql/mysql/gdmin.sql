@@ -32,7 +32,7 @@ CREATE TABLE `casbin_rule`  (
   `v5` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL,
   PRIMARY KEY (`id`) USING BTREE,
   UNIQUE INDEX `idx_casbin_rule`(`ptype` ASC, `v0` ASC, `v1` ASC, `v2` ASC, `v3` ASC, `v4` ASC, `v5` ASC) USING BTREE
-) ENGINE = InnoDB AUTO_INCREMENT = 20 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+) ENGINE = InnoDB AUTO_INCREMENT = 26 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
 -- Records of casbin_rule
@@ -56,6 +56,32 @@ INSERT INTO `casbin_rule` VALUES (16, 'p', 'r:1', 'sys:user:assignRoles', '6', '
 INSERT INTO `casbin_rule` VALUES (17, 'p', 'r:1', 'sys:user:delete', '8', '', '', '');
 INSERT INTO `casbin_rule` VALUES (19, 'p', 'r:1', 'sys:user:edit', '5', '', '', '');
 INSERT INTO `casbin_rule` VALUES (18, 'p', 'r:1', 'sys:user:resetPwd', '7', '', '', '');
+INSERT INTO `casbin_rule` VALUES (20, 'p', 'r:1', 'sys:role:restore', '19', '', '', '');
+INSERT INTO `casbin_rule` VALUES (21, 'p', 'r:1', 'sys:role:import', '20', '', '', '');
+INSERT INTO `casbin_rule` VALUES (22, 'p', 'r:1', 'sys:role:audit', '21', '', '', '');
+INSERT INTO `casbin_rule` VALUES (23, 'p', 'r:1', 'sys:role:assignUsers', '22', '', '', '');
+INSERT INTO `casbin_rule` VALUES (24, 'p', 'r:1', 'sys:role:export', '23', '', '', '');
+INSERT INTO `casbin_rule` VALUES (25, 'p', 'r:1', 'sys:role:repair', '24', '', '', '');
+
+-- ----------------------------
+-- Table structure for sys_dept
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_dept`;
+CREATE TABLE `sys_dept`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '部门ID',
+  `name` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '部门名称',
+  `parent_id` bigint UNSIGNED NULL DEFAULT 0 COMMENT '父部门ID',
+  `status` tinyint(1) NULL DEFAULT 1 COMMENT '状态(1:启用 2:禁用)',
+  `created_at` datetime(3) NULL DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NULL DEFAULT NULL COMMENT '修改时间',
+  `modify_user` varchar(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '修改人',
+  `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间',
+  `version` int NULL DEFAULT 0 COMMENT '版本号',
+  `created_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '创建人ID',
+  `updated_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '修改人ID',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_sys_dept_deleted_at`(`deleted_at` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 1 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
 -- Table structure for sys_menu
@@ -72,34 +98,76 @@ CREATE TABLE `sys_menu`  (
   `parent_id` bigint UNSIGNED NULL DEFAULT 0 COMMENT '父菜单ID',
   `status` tinyint(1) NULL DEFAULT 1 COMMENT '菜单状态(0:禁用,1:启用)',
   `meta` json NULL COMMENT '路由元数据',
+  `created_at` datetime(3) NULL DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime(3) NULL DEFAULT NULL COMMENT '修改时间',
   `modify_user` varchar(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '修改人',
   `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间',
+  `version` int NULL DEFAULT 0 COMMENT '版本号',
+  `created_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '创建人ID',
+  `updated_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '修改人ID',
   PRIMARY KEY (`id`) USING BTREE,
   INDEX `idx_sys_menu_deleted_at`(`deleted_at` ASC) USING BTREE
-) ENGINE = InnoDB AUTO_INCREMENT = 19 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+) ENGINE = InnoDB AUTO_INCREMENT = 25 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
 -- Records of sys_menu
 -- ----------------------------
-INSERT INTO `sys_menu` VALUES (1, '首页', 'home', 1, '', '/home', 'layout.base$view.home', 0, 1, '{\"icon\": \"mdi:monitor-dashboard\", \"order\": 1, \"i18nKey\": \"route.home\"}', '2024-07-25 10:47:55.157', '1', NULL);
-INSERT INTO `sys_menu` VALUES (2, '系统管理', 'system', 1, '', '/system', 'layout.base', 0, 1, '{\"icon\": \"carbon:cloud-service-management\", \"order\": 2, \"i18nKey\": \"route.system\"}', '2024-07-25 10:50:04.754', '1', NULL);
-INSERT INTO `sys_menu` VALUES (3, '用户管理', 'system_user', 2, 'sys:user', '/system/user', 'view.system_user', 2, 1, '{\"icon\": \"ic:round-manage-accounts\", \"order\": 1, \"i18nKey\": \"route.system_user\"}', '2024-07-31 13:06:24.526', '1', NULL);
-INSERT INTO `sys_menu` VALUES (4, '新增用户', '', 3, 'sys:user:add', '', '', 3, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 16:31:15.887', '1', NULL);
-INSERT INTO `sys_menu` VALUES (5, '编辑用户', '', 3, 'sys:user:edit', '', '', 3, 1, '{\"order\": 1, \"i18nKey\": null}', '2024-07-30 16:58:40.209', '1', NULL);
-INSERT INTO `sys_menu` VALUES (6, '分配角色', '', 3, 'sys:user:assignRoles', '', '', 3, 1, '{\"order\": 2, \"i18nKey\": null}', '2024-07-30 17:02:26.964', '1', NULL);
-INSERT INTO `sys_menu` VALUES (7, '重置密码', '', 3, 'sys:user:resetPwd', '', '', 3, 1, '{\"order\": 4, \"i18nKey\": null}', '2024-07-30 17:04:57.943', '1', NULL);
-INSERT INTO `sys_menu` VALUES (8, '删除用户', '', 3, 'sys:user:delete', '', '', 3, 1, '{\"order\": 5, \"i18nKey\": null}', '2024-07-30 17:05:44.823', '1', NULL);
-INSERT INTO `sys_menu` VALUES (9, '角色管理', 'system_role', 2, 'sys:role', '/system/role', 'view.system_role', 2, 1, '{\"icon\": \"carbon:user-role\", \"order\": 2, \"i18nKey\": \"route.system_role\"}', '2024-07-31 13:12:02.585', '1', NULL);
-INSERT INTO `sys_menu` VALUES (10, '新增角色', '', 3, 'sys:role:add', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '1', NULL);
-INSERT INTO `sys_menu` VALUES (11, '编辑角色', '', 3, 'sys:role:edit', '', '', 9, 1, '{\"order\": 1, \"i18nKey\": null}', '2024-07-30 17:15:13.891', '1', NULL);
-INSERT INTO `sys_menu` VALUES (12, '分配权限', '', 3, 'sys:role:assignMenus', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:17:27.685', '1', NULL);
-INSERT INTO `sys_menu` VALUES (13, '删除角色', '', 3, 'sys:role:delete', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:18:07.425', '1', NULL);
-INSERT INTO `sys_menu` VALUES (14, '菜单管理', 'system_menu', 2, 'sys:menu', '/system/menu', 'view.system_menu', 2, 1, '{\"icon\": \"material-symbols:route\", \"order\": 3, \"i18nKey\": \"route.system_menu\"}', '2024-07-31 13:04:19.701', '1', NULL);
-INSERT INTO `sys_menu` VALUES (15, '新增菜单', '', 3, 'sys:menu:add', '', '', 14, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:19:10.776', '1', NULL);
-INSERT INTO `sys_menu` VALUES (16, '编辑菜单', '', 3, 'sys:menu:edit', '', '', 14, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:19:35.597', '1', NULL);
-INSERT INTO `sys_menu` VALUES (17, '删除菜单', '', 3, 'sys:menu:delete', '', '', 14, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:19:58.259', '1', NULL);
-INSERT INTO `sys_menu` VALUES (18, '关于', 'about', 1, '', '/about', 'layout.base$view.about', 0, 1, '{\"icon\": \"fluent:book-information-24-regular\", \"order\": 10, \"i18nKey\": \"route.about\"}', '2024-07-31 11:18:37.165', '1', NULL);
+INSERT INTO `sys_menu` VALUES (1, '首页', 'home', 1, '', '/home', 'layout.base$view.home', 0, 1, '{\"icon\": \"mdi:monitor-dashboard\", \"order\": 1, \"i18nKey\": \"route.home\"}', '2024-07-25 10:47:55.157', '2024-07-25 10:47:55.157', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (2, '系统管理', 'system', 1, '', '/system', 'layout.base', 0, 1, '{\"icon\": \"carbon:cloud-service-management\", \"order\": 2, \"i18nKey\": \"route.system\"}', '2024-07-25 10:50:04.754', '2024-07-25 10:50:04.754', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (3, '用户管理', 'system_user', 2, 'sys:user', '/system/user', 'view.system_user', 2, 1, '{\"icon\": \"ic:round-manage-accounts\", \"order\": 1, \"i18nKey\": \"route.system_user\"}', '2024-07-31 13:06:24.526', '2024-07-31 13:06:24.526', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (4, '新增用户', '', 3, 'sys:user:add', '', '', 3, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 16:31:15.887', '2024-07-30 16:31:15.887', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (5, '编辑用户', '', 3, 'sys:user:edit', '', '', 3, 1, '{\"order\": 1, \"i18nKey\": null}', '2024-07-30 16:58:40.209', '2024-07-30 16:58:40.209', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (6, '分配角色', '', 3, 'sys:user:assignRoles', '', '', 3, 1, '{\"order\": 2, \"i18nKey\": null}', '2024-07-30 17:02:26.964', '2024-07-30 17:02:26.964', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (7, '重置密码', '', 3, 'sys:user:resetPwd', '', '', 3, 1, '{\"order\": 4, \"i18nKey\": null}', '2024-07-30 17:04:57.943', '2024-07-30 17:04:57.943', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (8, '删除用户', '', 3, 'sys:user:delete', '', '', 3, 1, '{\"order\": 5, \"i18nKey\": null}', '2024-07-30 17:05:44.823', '2024-07-30 17:05:44.823', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (9, '角色管理', 'system_role', 2, 'sys:role', '/system/role', 'view.system_role', 2, 1, '{\"icon\": \"carbon:user-role\", \"order\": 2, \"i18nKey\": \"route.system_role\"}', '2024-07-31 13:12:02.585', '2024-07-31 13:12:02.585', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (10, '新增角色', '', 3, 'sys:role:add', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '2024-07-30 17:14:30.884', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (11, '编辑角色', '', 3, 'sys:role:edit', '', '', 9, 1, '{\"order\": 1, \"i18nKey\": null}', '2024-07-30 17:15:13.891', '2024-07-30 17:15:13.891', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (12, '分配权限', '', 3, 'sys:role:assignMenus', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:17:27.685', '2024-07-30 17:17:27.685', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (13, '删除角色', '', 3, 'sys:role:delete', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:18:07.425', '2024-07-30 17:18:07.425', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (14, '菜单管理', 'system_menu', 2, 'sys:menu', '/system/menu', 'view.system_menu', 2, 1, '{\"icon\": \"material-symbols:route\", \"order\": 3, \"i18nKey\": \"route.system_menu\"}', '2024-07-31 13:04:19.701', '2024-07-31 13:04:19.701', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (15, '新增菜单', '', 3, 'sys:menu:add', '', '', 14, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:19:10.776', '2024-07-30 17:19:10.776', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (16, '编辑菜单', '', 3, 'sys:menu:edit', '', '', 14, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:19:35.597', '2024-07-30 17:19:35.597', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (17, '删除菜单', '', 3, 'sys:menu:delete', '', '', 14, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:19:58.259', '2024-07-30 17:19:58.259', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (18, '关于', 'about', 1, '', '/about', 'layout.base$view.about', 0, 1, '{\"icon\": \"fluent:book-information-24-regular\", \"order\": 10, \"i18nKey\": \"route.about\"}', '2024-07-31 11:18:37.165', '2024-07-31 11:18:37.165', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (19, '恢复角色', '', 3, 'sys:role:restore', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '2024-07-30 17:14:30.884', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (20, '导入角色', '', 3, 'sys:role:import', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '2024-07-30 17:14:30.884', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (21, '角色操作日志', '', 3, 'sys:role:audit', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '2024-07-30 17:14:30.884', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (22, '分配用户', '', 3, 'sys:role:assignUsers', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '2024-07-30 17:14:30.884', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (23, '导出角色', '', 3, 'sys:role:export', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '2024-07-30 17:14:30.884', '1', NULL, 0, 0, 0);
+INSERT INTO `sys_menu` VALUES (24, '修复角色关联', '', 3, 'sys:role:repair', '', '', 9, 1, '{\"order\": 0, \"i18nKey\": null}', '2024-07-30 17:14:30.884', '2024-07-30 17:14:30.884', '1', NULL, 0, 0, 0);
+
+-- ----------------------------
+-- Table structure for sys_operation_log
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_operation_log`;
+CREATE TABLE `sys_operation_log`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '日志ID',
+  `user_id` bigint UNSIGNED NULL DEFAULT NULL COMMENT '操作人ID',
+  `action` varchar(16) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '操作类型(create,update,delete)',
+  `resource` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '资源类型',
+  `resource_id` bigint UNSIGNED NULL DEFAULT NULL COMMENT '资源ID',
+  `diff` json NULL COMMENT '变更的字段',
+  `created_at` datetime(3) NULL DEFAULT NULL COMMENT '操作时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_sys_operation_log_resource`(`resource` ASC, `resource_id` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 1 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for sys_role_history
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_role_history`;
+CREATE TABLE `sys_role_history`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '历史ID',
+  `role_id` bigint UNSIGNED NULL DEFAULT NULL COMMENT '角色ID',
+  `revision` bigint UNSIGNED NULL DEFAULT NULL COMMENT '修订号',
+  `action` varchar(16) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '操作类型(create,update,delete)',
+  `snapshot` json NULL COMMENT '角色数据快照',
+  `user_id` bigint UNSIGNED NULL DEFAULT NULL COMMENT '操作人ID',
+  `created_at` datetime(3) NULL DEFAULT NULL COMMENT '修订时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `idx_sys_role_history_revision`(`role_id` ASC, `revision` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 1 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
 -- Table structure for sys_role
@@ -111,9 +179,15 @@ CREATE TABLE `sys_role`  (
   `code` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '角色标识',
   `status` tinyint(1) NULL DEFAULT 1 COMMENT '状态(1:启用 2:禁用)',
   `desc` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '备注',
+  `data_scope` tinyint(1) NULL DEFAULT 1 COMMENT '数据权限范围(1:全部,2:本部门及以下,3:本部门,4:仅本人,5:自定义)',
+  `parent_id` bigint UNSIGNED NULL DEFAULT 0 COMMENT '父角色ID(0:无父角色)',
+  `created_at` datetime(3) NULL DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime(3) NULL DEFAULT NULL COMMENT '修改时间',
   `modify_user` varchar(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '修改人',
   `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间',
+  `version` int NULL DEFAULT 0 COMMENT '版本号',
+  `created_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '创建人ID',
+  `updated_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '修改人ID',
   PRIMARY KEY (`id`) USING BTREE,
   INDEX `idx_sys_role_deleted_at`(`deleted_at` ASC) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 2 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
@@ -121,7 +195,56 @@ CREATE TABLE `sys_role`  (
 -- ----------------------------
 -- Records of sys_role
 -- ----------------------------
-INSERT INTO `sys_role` VALUES (1, '超级管理员', 'super_admin', 1, '超级管理员', '2024-07-29 15:56:36.859', NULL, NULL);
+INSERT INTO `sys_role` VALUES (1, '超级管理员', 'super_admin', 1, '超级管理员', 1, 0, '2024-07-29 15:56:36.859', '2024-07-29 15:56:36.859', NULL, NULL, 0, 0, 0);
+
+-- ----------------------------
+-- Table structure for sys_role_dept
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_role_dept`;
+CREATE TABLE `sys_role_dept`  (
+  `sys_role_id` bigint UNSIGNED NOT NULL COMMENT '角色ID',
+  `sys_dept_id` bigint UNSIGNED NOT NULL COMMENT '部门ID',
+  PRIMARY KEY (`sys_role_id`, `sys_dept_id`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Table structure for sys_role_menu
+-- ----------------------------
+DROP TABLE IF EXISTS `sys_role_menu`;
+CREATE TABLE `sys_role_menu`  (
+  `sys_role_id` bigint UNSIGNED NOT NULL COMMENT '角色ID',
+  `sys_menu_id` bigint UNSIGNED NOT NULL COMMENT '菜单ID',
+  PRIMARY KEY (`sys_role_id`, `sys_menu_id`) USING BTREE,
+  INDEX `idx_sys_role_menu_sys_menu_id`(`sys_menu_id` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Records of sys_role_menu
+-- ----------------------------
+INSERT INTO `sys_role_menu` VALUES (1, 1);
+INSERT INTO `sys_role_menu` VALUES (1, 2);
+INSERT INTO `sys_role_menu` VALUES (1, 3);
+INSERT INTO `sys_role_menu` VALUES (1, 4);
+INSERT INTO `sys_role_menu` VALUES (1, 5);
+INSERT INTO `sys_role_menu` VALUES (1, 6);
+INSERT INTO `sys_role_menu` VALUES (1, 7);
+INSERT INTO `sys_role_menu` VALUES (1, 8);
+INSERT INTO `sys_role_menu` VALUES (1, 9);
+INSERT INTO `sys_role_menu` VALUES (1, 10);
+INSERT INTO `sys_role_menu` VALUES (1, 11);
+INSERT INTO `sys_role_menu` VALUES (1, 12);
+INSERT INTO `sys_role_menu` VALUES (1, 13);
+INSERT INTO `sys_role_menu` VALUES (1, 14);
+INSERT INTO `sys_role_menu` VALUES (1, 15);
+INSERT INTO `sys_role_menu` VALUES (1, 16);
+INSERT INTO `sys_role_menu` VALUES (1, 17);
+INSERT INTO `sys_role_menu` VALUES (1, 18);
+INSERT INTO `sys_role_menu` VALUES (1, 19);
+INSERT INTO `sys_role_menu` VALUES (1, 20);
+INSERT INTO `sys_role_menu` VALUES (1, 21);
+INSERT INTO `sys_role_menu` VALUES (1, 22);
+INSERT INTO `sys_role_menu` VALUES (1, 23);
+INSERT INTO `sys_role_menu` VALUES (1, 24);
 
 -- ----------------------------
 -- Table structure for sys_user
@@ -136,9 +259,15 @@ CREATE TABLE `sys_user`  (
   `phone` varchar(16) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '联系电话',
   `email` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '邮箱',
   `status` tinyint(1) NULL DEFAULT 1 COMMENT '用户状态(1:正常,2:停用)',
+  `dept_id` bigint UNSIGNED NULL DEFAULT 0 COMMENT '部门ID',
+  `token_version` int NULL DEFAULT 0 COMMENT '令牌版本号',
+  `created_at` datetime(3) NULL DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime(3) NULL DEFAULT NULL COMMENT '修改时间',
   `modify_user` varchar(256) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL COMMENT '修改人',
   `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间',
+  `version` int NULL DEFAULT 0 COMMENT '版本号',
+  `created_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '创建人ID',
+  `updated_by` bigint UNSIGNED NULL DEFAULT 0 COMMENT '修改人ID',
   PRIMARY KEY (`id`) USING BTREE,
   INDEX `idx_sys_user_username`(`username` ASC) USING BTREE,
   INDEX `idx_sys_user_deleted_at`(`deleted_at` ASC) USING BTREE
@@ -147,7 +276,7 @@ CREATE TABLE `sys_user`  (
 -- ----------------------------
 -- Records of sys_user
 -- ----------------------------
-INSERT INTO `sys_user` VALUES (1, 'gdmin', '$2a$10$cl.N0OlfZQPGARJrxDJpzuJ1ZnEXCAotI1o8X6yWvYC5fZihKd8Oe', 'gdmin', 1, '13800138000', 'admin@localhost', 1, '2024-07-30 17:23:59.789', NULL, NULL);
+INSERT INTO `sys_user` VALUES (1, 'gdmin', '$2a$10$cl.N0OlfZQPGARJrxDJpzuJ1ZnEXCAotI1o8X6yWvYC5fZihKd8Oe', 'gdmin', 1, '13800138000', 'admin@localhost', 1, 0, 0, '2024-07-30 17:23:59.789', '2024-07-30 17:23:59.789', NULL, NULL, 0, 0, 0);
 
 -- ----------------------------
 -- Table structure for sys_user_role